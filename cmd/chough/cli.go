@@ -6,8 +6,17 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"unicode/utf8"
+
+	"github.com/hyperpuncher/chough/internal/asr"
+	"github.com/hyperpuncher/chough/internal/audio"
+	"github.com/hyperpuncher/chough/internal/config"
+	"github.com/hyperpuncher/chough/internal/models"
+	"github.com/hyperpuncher/chough/internal/output"
+	"github.com/hyperpuncher/chough/internal/server"
+	"github.com/hyperpuncher/chough/internal/types"
 )
 
 var (
@@ -27,20 +36,74 @@ var (
 )
 
 type cliOptions struct {
-	// CLI mode
-	AudioFile   string
+	Command string
+
+	// Transcribe
+	AudioFile   string // path, "-" for stdin, or an http(s) URL
+	MaxDownload int    // MB, for URL input
 	ChunkSize   int
 	Format      string
 	OutputFile  string
-	ShowVersion bool
 	RemoteMode  bool
-
-	// Server mode
-	ServerMode  bool
+	ConfigPath  string
+
+	// Channels
+	AudioStream   int // 0-based
+	Channel       int // 0-based, or audio.DownmixChannels
+	SplitChannels bool
+
+	// Preprocessing
+	Filters audio.Filters
+	VAD     bool
+
+	// Subtitle muxing
+	EmbedSubs   string
+	BurnIn      bool
+	SubLanguage string
+
+	// Output
+	MinConfidence  float64
+	LowConfidence  string
+	Punctuate      bool
+	ITN            bool
+	Task           string // transcribe or translate (Whisper)
+	Language       string
+	DetectLanguage bool
+	Cues           output.CueOptions
+	Text           output.TextOptions
+
+	// Shared settings
+	Model            string
+	RemoteURL        string
+	PunctuationModel string
+	LanguageIDModel  string
+	VADModel         string
+
+	// ASR
+	Threads        int
+	Provider       string
+	DecodingMethod string
+	MaxActivePaths int
+	BlankPenalty   float64
+	HotwordsFile   string
+	HotwordsScore  float64
+
+	// Remote
+	RemoteDecoding remoteDecoding
+
+	// Serve
 	ServerHost  string
 	ServerPort  int
 	Workers     int
 	MaxUploadMB int
+	URLPolicy   server.URLPolicy
+
+	// Models
+	ModelsAction string
+
+	// Config
+	ConfigAction string
+	Config       *config.Config // resolved settings, flags applied
 }
 
 type cliFlag struct {
@@ -57,20 +120,157 @@ type usageRow struct {
 	desc       string
 }
 
+type cliCommand struct {
+	name    string
+	usage   []string
+	summary string
+	flags   []cliFlag
+}
+
+var configFlag = cliFlag{long: "config", arg: "file", description: "config file", defaultVal: "$XDG_CONFIG_HOME/chough/config.toml"}
+
 var usageFlags = []cliFlag{
 	{short: "c", long: "chunk-size", arg: "int", description: "chunk size in seconds", defaultVal: "60"},
-	{short: "f", long: "format", arg: "string", description: "output format: text, json, vtt", defaultVal: "text"},
+	{short: "f", long: "format", arg: "string", description: "output format: " + strings.Join(output.Formats, ", "), defaultVal: "text"},
 	{short: "o", long: "output", arg: "file", description: "output file", defaultVal: "stdout"},
 	{short: "r", long: "remote", description: "transcribe via remote server using CHOUGH_URL"},
-	{long: "version", description: "show version"},
+	{long: "min-confidence", arg: "float", description: "flag words below this confidence (0-1) in text and subtitles", defaultVal: "0"},
+	{long: "low-confidence", arg: "string", description: "what to do with low-confidence words: mark, drop", defaultVal: "mark"},
+	{long: "punctuate", description: "restore punctuation and casing (downloads a punctuation model)"},
+	{long: "itn", description: "write numbers, currency and dates as digits (English)"},
+	{long: "task", arg: "string", description: "transcribe, or translate to English (Whisper models)", defaultVal: "transcribe"},
+	{long: "language", arg: "code", description: "spoken language (ISO 639-1), reported instead of detected"},
+	{long: "detect-language", description: "identify the spoken language of each chunk (downloads a Whisper model)"},
+	{long: "max-download", arg: "int", description: "max size in MB of an http(s) input, downloaded before transcribing", defaultVal: "1024"},
+	{long: "audio-stream", arg: "int", description: "audio stream to transcribe, 0 is the first", defaultVal: "0"},
+	{long: "channel", arg: "string", description: "transcribe one channel: left, right or a number from 1", defaultVal: "all, downmixed"},
+	{long: "split-channels", description: "transcribe each channel separately, labelled as speakers"},
+	{long: "preprocess", arg: "string", description: "filter preset: " + strings.Join(audio.PresetNames, ", "), defaultVal: "none"},
+	{long: "highpass", arg: "float", description: "high-pass filter cutoff in Hz, replaces the preset's", defaultVal: "0"},
+	{long: "denoise", description: "reduce stationary background noise"},
+	{long: "loudnorm", description: "normalize loudness to -23 LUFS (EBU R128)"},
+	{long: "vad", description: "transcribe only speech found by voice activity detection (downloads a VAD model)"},
+	{long: "paragraph-gap", arg: "float", description: "pause in seconds that starts a new paragraph", defaultVal: "2"},
+	{long: "timestamps", description: "prefix sentences and paragraphs with [HH:MM:SS]"},
+	{long: "frame-rate", arg: "float", description: "timecode frame rate for edl and markers", defaultVal: "30"},
+	{long: "embed-subs", arg: "file", description: "also write a copy of the input video with subtitles (.mkv, .mp4, ...)"},
+	{long: "burn-in", description: "render the --embed-subs subtitles into the video (re-encodes)"},
+	{long: "sub-language", arg: "string", description: "ISO 639-2 language of the --embed-subs track", defaultVal: "transcript language"},
+	configFlag,
+}
+
+var modelFlag = cliFlag{long: "model", arg: "name|dir", description: "registry model (see chough models) or model dir", defaultVal: "$CHOUGH_MODEL"}
+
+var asrFlags = []cliFlag{
+	modelFlag,
+	{long: "threads", arg: "int", description: "inference threads", defaultVal: "4"},
+	{long: "provider", arg: "string", description: "onnxruntime provider: cpu, cuda, coreml", defaultVal: "cpu"},
+	{long: "decoding-method", arg: "string", description: "greedy or modified_beam_search", defaultVal: "greedy"},
+	{long: "max-active-paths", arg: "int", description: "beam size for modified_beam_search", defaultVal: "4"},
+	{long: "blank-penalty", arg: "float", description: "penalty applied to blank tokens", defaultVal: "0"},
+	{long: "hotwords-file", arg: "file", description: "phrases to boost, one per line (needs modified_beam_search)"},
+	{long: "hotwords-score", arg: "float", description: "bonus score per hotword token", defaultVal: "1.5"},
+}
+
+// remoteASRFlags are the decoding settings remote sends with the request
+var remoteASRFlags = []cliFlag{
+	{long: "decoding-method", arg: "string", description: "greedy or modified_beam_search", defaultVal: "server's"},
+	{long: "max-active-paths", arg: "int", description: "beam size for modified_beam_search", defaultVal: "server's"},
+	{long: "blank-penalty", arg: "float", description: "penalty applied to blank tokens", defaultVal: "server's"},
+	{long: "hotwords", arg: "list", description: "phrases to boost, comma-separated (needs modified_beam_search)"},
+}
+
+var cueFlags = []cliFlag{
+	{long: "max-cue-duration", arg: "float", description: "max cue duration in seconds", defaultVal: "5"},
+	{long: "min-cue-duration", arg: "float", description: "min cue duration, extended into silence", defaultVal: "0"},
+	{long: "max-line-chars", arg: "int", description: "max characters per line, 0 disables wrapping", defaultVal: "42"},
+	{long: "max-lines", arg: "int", description: "max lines per cue", defaultVal: "2"},
+	{long: "max-cps", arg: "float", description: "max characters per second, extends short cues", defaultVal: "0"},
+	{long: "min-cue-gap", arg: "float", description: "min gap between cues in seconds", defaultVal: "0"},
+	{long: "split-on-comma", description: "also end cues at commas"},
+	{long: "split-on-pause", arg: "float", description: "end cues at pauses longer than this (seconds)", defaultVal: "0"},
+	{long: "cue-padding", arg: "float", description: "lead-in and lead-out added to cues in seconds", defaultVal: "0"},
+	{long: "extend-into-silence", description: "keep cues on screen until the next one starts"},
 }
 
 var serverFlags = []cliFlag{
-	{long: "server", description: "run in server mode"},
 	{long: "host", arg: "string", description: "server host", defaultVal: "0.0.0.0"},
 	{long: "port", arg: "int", description: "server port", defaultVal: "8080"},
 	{long: "workers", arg: "int", description: "concurrent workers", defaultVal: "2"},
 	{long: "max-upload", arg: "int", description: "max upload size in MB", defaultVal: "1024"},
+	{long: "punctuate", description: "load the punctuation model so requests can set punctuate"},
+	{long: "detect-language", description: "load the language identification model so requests can set detect_language"},
+	{long: "vad", description: "load the VAD model so requests can set vad"},
+	{long: "url-schemes", arg: "list", description: "schemes allowed in request urls", defaultVal: "http,https"},
+	{long: "url-allow", arg: "list", description: "only download request urls from these hosts or networks", defaultVal: "any public host"},
+	{long: "url-deny", arg: "list", description: "never download request urls from these hosts or networks"},
+	{long: "url-allow-private", description: "allow request urls on loopback, private and link-local addresses"},
+	configFlag,
+}
+
+var commands = []cliCommand{
+	{
+		name:    "transcribe",
+		usage:   []string{"chough transcribe [flags] [audio-file]", "chough [flags] [audio-file]", "cat audio | chough [flags]"},
+		summary: "transcribe audio locally (default)",
+		flags:   concatFlags(usageFlags, cueFlags, asrFlags),
+	},
+	{
+		name:    "remote",
+		usage:   []string{"chough remote [flags] [audio-file]"},
+		summary: "transcribe via remote server using CHOUGH_URL",
+		flags:   concatFlags(withoutFlag(usageFlags, "remote"), cueFlags, remoteASRFlags),
+	},
+	{
+		name:    "serve",
+		usage:   []string{"chough serve [flags]"},
+		summary: "run HTTP server",
+		flags:   concatFlags(serverFlags, cueFlags, asrFlags),
+	},
+	{
+		name:    "models",
+		usage:   []string{"chough models [list|path|download]"},
+		summary: "show or download models",
+		flags:   []cliFlag{modelFlag, configFlag},
+	},
+	{
+		name:    "config",
+		usage:   []string{"chough config show [transcribe|remote|serve] [flags]"},
+		summary: "print effective settings, with the command's flags applied",
+		flags:   []cliFlag{configFlag},
+	},
+	{
+		name:    "version",
+		usage:   []string{"chough version"},
+		summary: "show version",
+	},
+}
+
+func concatFlags(tables ...[]cliFlag) []cliFlag {
+	var out []cliFlag
+	for _, t := range tables {
+		out = append(out, t...)
+	}
+	return out
+}
+
+func withoutFlag(flags []cliFlag, long string) []cliFlag {
+	out := make([]cliFlag, 0, len(flags))
+	for _, f := range flags {
+		if f.long != long {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func findCommand(name string) *cliCommand {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
 }
 
 func init() {
@@ -98,75 +298,552 @@ func disableColors() {
 	cyan = ""
 }
 
+// splitCommand returns the subcommand and its arguments. Anything that is not
+// a known command is shorthand for transcribe, reported as an empty name so
+// that errors show the general usage.
+func splitCommand(args []string) (string, []string) {
+	if len(args) == 0 {
+		return "", args
+	}
+	switch args[0] {
+	case "--version", "-version":
+		return "version", args[1:]
+	case "--server", "-server":
+		// Kept for compatibility with pre-subcommand invocations
+		return "serve", args[1:]
+	}
+	if findCommand(args[0]) != nil {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
 func parseCLI(args []string) (cliOptions, error) {
-	fs := flag.NewFlagSet("chough", flag.ContinueOnError)
-	fs.SetOutput(io.Discard)
+	if len(args) > 0 && args[0] == "help" {
+		name := ""
+		if len(args) > 1 {
+			name = args[1]
+		}
+		printCommandUsage(name)
+		return cliOptions{}, errShowHelp
+	}
 
-	// CLI flags
-	chunkSize := fs.Int("c", 60, "chunk size in seconds")
-	fs.IntVar(chunkSize, "chunk-size", 60, "chunk size in seconds")
-	format := fs.String("f", "text", "output format (text, json, vtt)")
-	fs.StringVar(format, "format", "text", "output format (text, json, vtt)")
-	outputFile := fs.String("o", "", "output file")
-	fs.StringVar(outputFile, "output", "", "output file")
-	showVersion := fs.Bool("version", false, "show version")
-	remoteMode := fs.Bool("r", false, "transcribe via remote server using CHOUGH_URL")
-	fs.BoolVar(remoteMode, "remote", false, "transcribe via remote server using CHOUGH_URL")
-
-	// Server flags
-	serverMode := fs.Bool("server", false, "run in server mode")
-	serverHost := fs.String("host", "0.0.0.0", "server host")
-	serverPort := fs.Int("port", 8080, "server port")
-	workers := fs.Int("workers", 2, "concurrent workers")
-	maxUploadMB := fs.Int("max-upload", 1024, "max upload size in MB")
-
-	fs.Usage = func() {
-		printUsage()
+	name, rest := splitCommand(args)
+	opts := cliOptions{Command: name}
+
+	switch name {
+	case "", "transcribe", "remote":
+		return parseTranscribe(opts, rest)
+	case "serve":
+		return parseServe(opts, rest)
+	case "models":
+		return parseModels(opts, rest)
+	case "config":
+		return parseConfig(opts, rest)
+	case "version":
+		if len(rest) > 0 {
+			return opts, fmt.Errorf("%w: version takes no arguments", errInvalidArgs)
+		}
+		return opts, nil
+	default:
+		return opts, fmt.Errorf("%w: unknown command %q", errInvalidArgs, name)
 	}
+}
+
+func newFlagSet(opts cliOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("chough "+opts.Command, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	// Usage is printed by run, once, for both help and parse errors
+	fs.Usage = func() {}
+	return fs
+}
 
+func parseFlags(fs *flag.FlagSet, command string, args []string) error {
 	if err := fs.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
-			return cliOptions{}, errShowHelp
+			printCommandUsage(command)
+			return errShowHelp
 		}
-		return cliOptions{}, fmt.Errorf("%w: %v", errInvalidArgs, err)
+		return fmt.Errorf("%w: %v", errInvalidArgs, err)
 	}
+	return nil
+}
 
-	opts := cliOptions{
-		ChunkSize:   *chunkSize,
-		Format:      strings.ToLower(*format),
-		OutputFile:  *outputFile,
-		ShowVersion: *showVersion,
-		RemoteMode:  *remoteMode,
-		ServerMode:  *serverMode,
-		ServerHost:  *serverHost,
-		ServerPort:  *serverPort,
-		Workers:     *workers,
-		MaxUploadMB: *maxUploadMB,
+// transcribeFlagSet binds the transcribe (or remote) flags to cfg, so that
+// flags override file and env values
+func transcribeFlagSet(opts *cliOptions, cfg *config.Config) *flag.FlagSet {
+	fs := newFlagSet(*opts)
+	fs.IntVar(&cfg.Transcribe.ChunkSize, "c", cfg.Transcribe.ChunkSize, "chunk size in seconds")
+	fs.IntVar(&cfg.Transcribe.ChunkSize, "chunk-size", cfg.Transcribe.ChunkSize, "chunk size in seconds")
+	fs.StringVar(&cfg.Transcribe.Format, "f", cfg.Transcribe.Format, "output format")
+	fs.StringVar(&cfg.Transcribe.Format, "format", cfg.Transcribe.Format, "output format")
+	fs.StringVar(&cfg.Transcribe.Output, "o", cfg.Transcribe.Output, "output file")
+	fs.StringVar(&cfg.Transcribe.Output, "output", cfg.Transcribe.Output, "output file")
+	if opts.Command != "remote" {
+		fs.BoolVar(&cfg.Transcribe.Remote, "r", cfg.Transcribe.Remote, "transcribe via remote server using CHOUGH_URL")
+		fs.BoolVar(&cfg.Transcribe.Remote, "remote", cfg.Transcribe.Remote, "transcribe via remote server using CHOUGH_URL")
 	}
+	fs.Float64Var(&cfg.Transcribe.MinConfidence, "min-confidence", cfg.Transcribe.MinConfidence, "confidence threshold")
+	fs.StringVar(&cfg.Transcribe.LowConfidence, "low-confidence", cfg.Transcribe.LowConfidence, "mark or drop")
+	fs.BoolVar(&cfg.Transcribe.Punctuate, "punctuate", cfg.Transcribe.Punctuate, "restore punctuation and casing")
+	fs.BoolVar(&cfg.Transcribe.ITN, "itn", cfg.Transcribe.ITN, "inverse text normalization")
+	fs.StringVar(&cfg.Transcribe.Task, "task", cfg.Transcribe.Task, "transcribe or translate")
+	fs.StringVar(&cfg.Transcribe.Language, "language", cfg.Transcribe.Language, "spoken language")
+	fs.BoolVar(&cfg.Transcribe.DetectLanguage, "detect-language", cfg.Transcribe.DetectLanguage, "identify the spoken language")
+	fs.IntVar(&cfg.Transcribe.MaxDownload, "max-download", cfg.Transcribe.MaxDownload, "max download size in MB")
+	fs.IntVar(&cfg.Transcribe.AudioStream, "audio-stream", cfg.Transcribe.AudioStream, "audio stream to transcribe")
+	fs.StringVar(&cfg.Transcribe.Channel, "channel", cfg.Transcribe.Channel, "channel to transcribe")
+	fs.BoolVar(&cfg.Transcribe.SplitChannels, "split-channels", cfg.Transcribe.SplitChannels, "transcribe channels separately")
+	fs.StringVar(&cfg.Transcribe.Preprocess, "preprocess", cfg.Transcribe.Preprocess, "filter preset")
+	fs.Float64Var(&cfg.Transcribe.HighPass, "highpass", cfg.Transcribe.HighPass, "high-pass cutoff")
+	fs.BoolVar(&cfg.Transcribe.Denoise, "denoise", cfg.Transcribe.Denoise, "reduce noise")
+	fs.BoolVar(&cfg.Transcribe.Loudnorm, "loudnorm", cfg.Transcribe.Loudnorm, "normalize loudness")
+	fs.BoolVar(&cfg.Transcribe.VAD, "vad", cfg.Transcribe.VAD, "transcribe only detected speech")
+	fs.Float64Var(&cfg.Transcribe.ParagraphGap, "paragraph-gap", cfg.Transcribe.ParagraphGap, "paragraph gap")
+	fs.BoolVar(&cfg.Transcribe.Timestamps, "timestamps", cfg.Transcribe.Timestamps, "timestamp prefixes")
+	fs.Float64Var(&cfg.Transcribe.FrameRate, "frame-rate", cfg.Transcribe.FrameRate, "timecode frame rate")
+	fs.StringVar(&cfg.Transcribe.EmbedSubs, "embed-subs", cfg.Transcribe.EmbedSubs, "output video with subtitles")
+	fs.BoolVar(&cfg.Transcribe.BurnIn, "burn-in", cfg.Transcribe.BurnIn, "burn subtitles into the video")
+	fs.StringVar(&cfg.Transcribe.SubLanguage, "sub-language", cfg.Transcribe.SubLanguage, "subtitle track language")
+	fs.StringVar(&opts.ConfigPath, "config", "", "config file")
+	bindCueFlags(fs, &cfg.Subtitles)
+	if opts.Command == "remote" {
+		bindDecodingFlags(fs, &cfg.ASR)
+		fs.Func("hotwords", "phrases to boost", func(v string) error {
+			opts.RemoteDecoding.Hotwords = append(opts.RemoteDecoding.Hotwords, splitFlagList(v)...)
+			return nil
+		})
+	} else {
+		fs.StringVar(&cfg.Model, "model", cfg.Model, "model name or dir")
+		bindASRFlags(fs, &cfg.ASR)
+	}
+	return fs
+}
 
-	if opts.ShowVersion || opts.ServerMode {
-		return opts, nil
+func parseTranscribe(opts cliOptions, args []string) (cliOptions, error) {
+	cfg, err := loadConfig(configPathFromArgs(args))
+	if err != nil {
+		return opts, err
 	}
 
+	fs := transcribeFlagSet(&opts, cfg)
+	if err := parseFlags(fs, opts.Command, args); err != nil {
+		return opts, err
+	}
+
+	opts.ChunkSize = cfg.Transcribe.ChunkSize
+	opts.Format = strings.ToLower(cfg.Transcribe.Format)
+	opts.OutputFile = cfg.Transcribe.Output
+	opts.RemoteMode = cfg.Transcribe.Remote || opts.Command == "remote"
+	opts.EmbedSubs = cfg.Transcribe.EmbedSubs
+	opts.BurnIn = cfg.Transcribe.BurnIn
+	opts.MinConfidence = cfg.Transcribe.MinConfidence
+	opts.LowConfidence = strings.ToLower(cfg.Transcribe.LowConfidence)
+	opts.Punctuate = cfg.Transcribe.Punctuate
+	opts.ITN = cfg.Transcribe.ITN
+	opts.Language = strings.ToLower(cfg.Transcribe.Language)
+	opts.DetectLanguage = cfg.Transcribe.DetectLanguage
+	opts.SubLanguage = strings.ToLower(cfg.Transcribe.SubLanguage)
+	opts.MaxDownload = cfg.Transcribe.MaxDownload
+	opts.AudioStream = cfg.Transcribe.AudioStream
+	opts.SplitChannels = cfg.Transcribe.SplitChannels
+	opts.VAD = cfg.Transcribe.VAD
+	opts.Text = output.TextOptions{
+		ParagraphGap: cfg.Transcribe.ParagraphGap,
+		Timestamps:   cfg.Transcribe.Timestamps,
+		FrameRate:    cfg.Transcribe.FrameRate,
+	}
+	if err := opts.applyConfig(cfg); err != nil {
+		return opts, err
+	}
+
+	// Set after applyConfig, which validates the shared ASR settings; the
+	// local model is checked below and the server checks its own
+	task, err := asr.ParseTask(strings.ToLower(cfg.Transcribe.Task))
+	if err != nil {
+		return opts, fmt.Errorf("%w: %v", errInvalidArgs, err)
+	}
+	opts.Task = task
+	if !opts.RemoteMode {
+		if err := opts.asrConfig().Validate(); err != nil {
+			return opts, fmt.Errorf("%w: %v", errInvalidArgs, err)
+		}
+	} else {
+		// Only decoding settings given as flags are sent; the server's own
+		// apply to the rest
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "decoding-method":
+				opts.RemoteDecoding.Method = opts.DecodingMethod
+			case "max-active-paths":
+				opts.RemoteDecoding.MaxActivePaths = opts.MaxActivePaths
+			case "blank-penalty":
+				penalty := opts.BlankPenalty
+				opts.RemoteDecoding.BlankPenalty = &penalty
+			}
+		})
+	}
+
+	if opts.MinConfidence < 0 || opts.MinConfidence > 1 {
+		return opts, fmt.Errorf("%w: --min-confidence must be between 0 and 1", errInvalidArgs)
+	}
+	if opts.LowConfidence != output.LowConfidenceMark && opts.LowConfidence != output.LowConfidenceDrop {
+		return opts, fmt.Errorf("%w: unknown --low-confidence %q (valid: mark, drop)", errInvalidArgs, opts.LowConfidence)
+	}
+
+	if fs.NArg() > 1 {
+		return opts, fmt.Errorf("%w: expected one audio file, got %d arguments", errInvalidArgs, fs.NArg())
+	}
 	if fs.NArg() < 1 {
 		// Check if stdin has data for pipe mode
 		stat, err := os.Stdin.Stat()
 		if err != nil || (stat.Mode()&os.ModeCharDevice) != 0 {
-			return cliOptions{}, fmt.Errorf("%w: audio file is required (or pipe audio to stdin)", errInvalidArgs)
+			return opts, fmt.Errorf("%w: audio file is required (or pipe audio to stdin)", errInvalidArgs)
 		}
 		opts.AudioFile = "-"
 	} else {
 		opts.AudioFile = fs.Arg(0)
 	}
 
-	switch opts.Format {
-	case "text", "json", "vtt":
+	if !output.IsFormat(opts.Format) {
+		return opts, fmt.Errorf("%w: unknown format %q (valid: %s)", errInvalidArgs, opts.Format, strings.Join(output.Formats, ", "))
+	}
+	if err := opts.Text.Validate(); err != nil {
+		return opts, fmt.Errorf("%w: %v", errInvalidArgs, err)
+	}
+	if err := opts.validateEmbedSubs(); err != nil {
+		return opts, err
+	}
+	if opts.MaxDownload <= 0 {
+		return opts, fmt.Errorf("%w: --max-download must be at least 1", errInvalidArgs)
+	}
+	if opts.AudioStream < 0 {
+		return opts, fmt.Errorf("%w: --audio-stream must be 0 or more", errInvalidArgs)
+	}
+	if opts.Channel, err = audio.ParseChannel(cfg.Transcribe.Channel); err != nil {
+		return opts, fmt.Errorf("%w: --channel: %v", errInvalidArgs, err)
+	}
+	if opts.SplitChannels && opts.Channel != audio.DownmixChannels {
+		return opts, fmt.Errorf("%w: --channel and --split-channels are mutually exclusive", errInvalidArgs)
+	}
+	explicit := audio.Filters{HighPass: cfg.Transcribe.HighPass, Denoise: cfg.Transcribe.Denoise, Loudnorm: cfg.Transcribe.Loudnorm}
+	if opts.Filters, err = audio.ResolveFilters(cfg.Transcribe.Preprocess, explicit); err != nil {
+		return opts, fmt.Errorf("%w: %v", errInvalidArgs, err)
+	}
+	if opts.Language != "" && !opts.RemoteMode {
+		if spec, ok := models.Lookup(opts.Model); ok && !spec.SupportsLanguage(opts.Language) {
+			return opts, fmt.Errorf("%w: %s does not support --language %q (valid: %s)", errInvalidArgs, spec.Name, opts.Language, strings.Join(spec.Languages, ", "))
+		}
+	}
+	return opts, nil
+}
+
+// serveFlagSet binds the serve flags to cfg, so that flags override file
+// and env values
+func serveFlagSet(opts *cliOptions, cfg *config.Config) *flag.FlagSet {
+	fs := newFlagSet(*opts)
+	fs.StringVar(&cfg.Server.Host, "host", cfg.Server.Host, "server host")
+	fs.IntVar(&cfg.Server.Port, "port", cfg.Server.Port, "server port")
+	fs.IntVar(&cfg.Server.Workers, "workers", cfg.Server.Workers, "concurrent workers")
+	fs.IntVar(&cfg.Server.MaxUpload, "max-upload", cfg.Server.MaxUpload, "max upload size in MB")
+	fs.StringVar(&cfg.Model, "model", cfg.Model, "model name or dir")
+	fs.BoolVar(&cfg.Server.Punctuate, "punctuate", cfg.Server.Punctuate, "load the punctuation model")
+	fs.BoolVar(&cfg.Server.DetectLanguage, "detect-language", cfg.Server.DetectLanguage, "load the language identification model")
+	fs.BoolVar(&cfg.Server.VAD, "vad", cfg.Server.VAD, "load the VAD model")
+	fs.Func("url-schemes", "schemes allowed in request urls", func(v string) error {
+		cfg.Server.URLSchemes = splitFlagList(v)
+		return nil
+	})
+	fs.Func("url-allow", "hosts request urls may use", func(v string) error {
+		cfg.Server.URLAllow = splitFlagList(v)
+		return nil
+	})
+	fs.Func("url-deny", "hosts request urls may not use", func(v string) error {
+		cfg.Server.URLDeny = splitFlagList(v)
+		return nil
+	})
+	fs.BoolVar(&cfg.Server.URLAllowPrivate, "url-allow-private", cfg.Server.URLAllowPrivate, "allow private request urls")
+	fs.StringVar(&opts.ConfigPath, "config", "", "config file")
+	bindCueFlags(fs, &cfg.Subtitles)
+	bindASRFlags(fs, &cfg.ASR)
+	return fs
+}
+
+func parseServe(opts cliOptions, args []string) (cliOptions, error) {
+	cfg, err := loadConfig(configPathFromArgs(args))
+	if err != nil {
+		return opts, err
+	}
+
+	fs := serveFlagSet(&opts, cfg)
+	if err := parseFlags(fs, opts.Command, args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("%w: serve takes no arguments, got %q", errInvalidArgs, fs.Arg(0))
+	}
+
+	opts.ServerHost = cfg.Server.Host
+	opts.ServerPort = cfg.Server.Port
+	opts.Workers = cfg.Server.Workers
+	opts.MaxUploadMB = cfg.Server.MaxUpload
+	opts.Punctuate = cfg.Server.Punctuate
+	opts.DetectLanguage = cfg.Server.DetectLanguage
+	opts.VAD = cfg.Server.VAD
+	opts.URLPolicy = server.URLPolicy{
+		Schemes:      lowerAll(cfg.Server.URLSchemes),
+		AllowHosts:   cfg.Server.URLAllow,
+		DenyHosts:    cfg.Server.URLDeny,
+		AllowPrivate: cfg.Server.URLAllowPrivate,
+	}
+	if err := opts.URLPolicy.Validate(); err != nil {
+		return opts, fmt.Errorf("%w: %v", errInvalidArgs, err)
+	}
+	if err := opts.applyConfig(cfg); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// splitFlagList splits a comma-separated flag value, dropping empty items
+func splitFlagList(v string) []string {
+	out := []string{}
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// lowerAll returns the items in lowercase
+func lowerAll(items []string) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = strings.ToLower(strings.TrimSpace(item))
+	}
+	return out
+}
+
+func parseModels(opts cliOptions, args []string) (cliOptions, error) {
+	cfg, err := loadConfig(configPathFromArgs(args))
+	if err != nil {
+		return opts, err
+	}
+
+	fs := newFlagSet(opts)
+	fs.StringVar(&cfg.Model, "model", cfg.Model, "model name or dir")
+	configPath := fs.String("config", "", "config file")
+	if err := parseFlags(fs, opts.Command, args); err != nil {
+		return opts, err
+	}
+
+	opts.ConfigPath = *configPath
+	if err := opts.applyConfig(cfg); err != nil {
+		return opts, err
+	}
+
+	opts.ModelsAction = "list"
+	if fs.NArg() > 0 {
+		opts.ModelsAction = fs.Arg(0)
+	}
+	switch opts.ModelsAction {
+	case "list", "path", "download":
 		return opts, nil
 	default:
-		return cliOptions{}, fmt.Errorf("%w: unknown format %q (valid: text, json, vtt)", errInvalidArgs, opts.Format)
+		return opts, fmt.Errorf("%w: unknown models action %q (valid: list, path, download)", errInvalidArgs, opts.ModelsAction)
+	}
+}
+
+func parseConfig(opts cliOptions, args []string) (cliOptions, error) {
+	fs := newFlagSet(opts)
+	fs.StringVar(&opts.ConfigPath, "config", "", "config file")
+	if err := parseFlags(fs, opts.Command, args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() == 0 {
+		return opts, fmt.Errorf("%w: missing config action (valid: show)", errInvalidArgs)
+	}
+	opts.ConfigAction = fs.Arg(0)
+	if opts.ConfigAction != "show" {
+		return opts, fmt.Errorf("%w: unknown config action %q (valid: show)", errInvalidArgs, opts.ConfigAction)
+	}
+
+	// The settings shown are a command's, transcribe by default, with its
+	// flags applied
+	cfg, err := loadConfig(configPathFromArgs(args))
+	if err != nil {
+		return opts, err
+	}
+	command, rest := "transcribe", fs.Args()[1:]
+	if len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+		command, rest = rest[0], rest[1:]
+	}
+	cmdOpts := cliOptions{Command: command, ConfigPath: opts.ConfigPath}
+	var cmdFlags *flag.FlagSet
+	switch command {
+	case "transcribe", "remote":
+		cmdFlags = transcribeFlagSet(&cmdOpts, cfg)
+	case "serve":
+		cmdFlags = serveFlagSet(&cmdOpts, cfg)
+	default:
+		return opts, fmt.Errorf("%w: unknown command %q (valid: transcribe, remote, serve)", errInvalidArgs, command)
+	}
+	if err := parseFlags(cmdFlags, opts.Command, rest); err != nil {
+		return opts, err
+	}
+	if cmdFlags.NArg() > 0 {
+		return opts, fmt.Errorf("%w: unexpected argument %q", errInvalidArgs, cmdFlags.Arg(0))
+	}
+
+	opts.ConfigPath = cmdOpts.ConfigPath
+	opts.Config = cfg
+	return opts, nil
+}
+
+// bindASRFlags binds recognizer flags directly to the loaded config so that
+// flags override file and env values
+func bindASRFlags(fs *flag.FlagSet, cfg *config.ASRConfig) {
+	fs.IntVar(&cfg.Threads, "threads", cfg.Threads, "inference threads")
+	fs.StringVar(&cfg.Provider, "provider", cfg.Provider, "onnxruntime provider")
+	bindDecodingFlags(fs, cfg)
+	fs.StringVar(&cfg.HotwordsFile, "hotwords-file", cfg.HotwordsFile, "hotwords file")
+	fs.Float64Var(&cfg.HotwordsScore, "hotwords-score", cfg.HotwordsScore, "bonus score per hotword token")
+}
+
+// bindDecodingFlags binds the decoding flags, which remote forwards
+func bindDecodingFlags(fs *flag.FlagSet, cfg *config.ASRConfig) {
+	fs.StringVar(&cfg.DecodingMethod, "decoding-method", cfg.DecodingMethod, "decoding method")
+	fs.IntVar(&cfg.MaxActivePaths, "max-active-paths", cfg.MaxActivePaths, "beam size for modified_beam_search")
+	fs.Float64Var(&cfg.BlankPenalty, "blank-penalty", cfg.BlankPenalty, "penalty applied to blank tokens")
+}
+
+// bindCueFlags binds subtitle segmentation flags to the loaded config
+func bindCueFlags(fs *flag.FlagSet, cues *config.SubtitlesConfig) {
+	fs.Float64Var(&cues.MaxCueDuration, "max-cue-duration", cues.MaxCueDuration, "max cue duration")
+	fs.Float64Var(&cues.MinCueDuration, "min-cue-duration", cues.MinCueDuration, "min cue duration")
+	fs.IntVar(&cues.MaxLineChars, "max-line-chars", cues.MaxLineChars, "max characters per line")
+	fs.IntVar(&cues.MaxLines, "max-lines", cues.MaxLines, "max lines per cue")
+	fs.Float64Var(&cues.MaxCPS, "max-cps", cues.MaxCPS, "max characters per second")
+	fs.Float64Var(&cues.MinCueGap, "min-cue-gap", cues.MinCueGap, "min gap between cues")
+	fs.BoolVar(&cues.SplitOnComma, "split-on-comma", cues.SplitOnComma, "end cues at commas")
+	fs.Float64Var(&cues.SplitOnPause, "split-on-pause", cues.SplitOnPause, "end cues at pauses")
+	fs.Float64Var(&cues.CuePadding, "cue-padding", cues.CuePadding, "lead-in and lead-out")
+	fs.BoolVar(&cues.ExtendIntoSilence, "extend-into-silence", cues.ExtendIntoSilence, "hold cues until the next one")
+}
+
+// applyConfig copies settings that are resolved through the config file
+func (o *cliOptions) applyConfig(cfg *config.Config) error {
+	method, err := asr.ParseDecodingMethod(strings.ToLower(cfg.ASR.DecodingMethod))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidArgs, err)
+	}
+
+	o.Model = cfg.Model
+	o.RemoteURL = cfg.URL
+	o.PunctuationModel = cfg.PunctuationModel
+	o.LanguageIDModel = cfg.LanguageIDModel
+	o.VADModel = cfg.VADModel
+	o.Threads = cfg.ASR.Threads
+	o.Provider = cfg.ASR.Provider
+	o.DecodingMethod = method
+	o.MaxActivePaths = cfg.ASR.MaxActivePaths
+	o.BlankPenalty = cfg.ASR.BlankPenalty
+	o.HotwordsFile = cfg.ASR.HotwordsFile
+	o.HotwordsScore = cfg.ASR.HotwordsScore
+	o.Cues = cueOptions(cfg.Subtitles)
+
+	if err := o.asrConfig().Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidArgs, err)
+	}
+	if err := o.Cues.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidArgs, err)
+	}
+	return nil
+}
+
+// outputOptions builds the formatting options for the given metadata
+func (o *cliOptions) outputOptions(meta *types.Metadata) output.Options {
+	return output.Options{
+		Metadata:      meta,
+		MinConfidence: float32(o.MinConfidence),
+		LowConfidence: o.LowConfidence,
+		Cues:          o.Cues,
+		Text:          o.Text,
+		AudioSource:   o.audioSource(),
 	}
 }
 
+// audioSource returns the audio file's path relative to the output file,
+// for the HTML player. Piped audio has no source and URLs are used as is.
+func (o *cliOptions) audioSource() string {
+	if o.AudioFile == "" || o.AudioFile == "-" {
+		return ""
+	}
+	if isURL(o.AudioFile) {
+		return o.AudioFile
+	}
+	if o.OutputFile == "" {
+		return filepath.ToSlash(o.AudioFile)
+	}
+	audio, err := filepath.Abs(o.AudioFile)
+	if err != nil {
+		return filepath.ToSlash(o.AudioFile)
+	}
+	dir, err := filepath.Abs(filepath.Dir(o.OutputFile))
+	if err != nil {
+		return filepath.ToSlash(o.AudioFile)
+	}
+	rel, err := filepath.Rel(dir, audio)
+	if err != nil {
+		return filepath.ToSlash(audio)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// asrConfig builds the recognizer config. ModelPath may be empty, in which
+// case the default model is used.
+func (o *cliOptions) asrConfig() *asr.Config {
+	cfg := asr.DefaultConfig(o.Model)
+	if spec, ok := models.Lookup(o.Model); ok {
+		cfg.Kind = spec.Kind
+	} else if kind := models.DetectKind(o.Model); kind != "" {
+		cfg.Kind = kind
+	}
+	if o.Task != "" {
+		cfg.Task = o.Task
+	}
+	cfg.NumThreads = o.Threads
+	cfg.Provider = o.Provider
+	cfg.DecodingMethod = o.DecodingMethod
+	cfg.MaxActivePaths = o.MaxActivePaths
+	cfg.BlankPenalty = float32(o.BlankPenalty)
+	cfg.HotwordsFile = o.HotwordsFile
+	cfg.HotwordsScore = float32(o.HotwordsScore)
+	return cfg
+}
+
+// configPathFromArgs finds --config before flag parsing, since the config
+// file provides the flag defaults.
+func configPathFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
 func formatFlagLabel(f cliFlag) string {
 	parts := make([]string, 0, 2)
 	if f.short != "" {
@@ -208,58 +885,94 @@ func printAlignedRows(rows []usageRow) {
 	}
 }
 
-func printUsage() {
-	fmt.Fprintf(os.Stderr, "%s🐦‍⬛ %schough%s\n\n", bold, magenta, reset)
-
-	fmt.Fprintf(os.Stderr, "%sCLI Usage:%s\n", bold, reset)
-	fmt.Fprintln(os.Stderr, "  chough [flags] [audio-file]")
-	fmt.Fprintln(os.Stderr, "  cat audio | chough [flags]")
-	fmt.Fprintln(os.Stderr)
-
-	fmt.Fprintf(os.Stderr, "%sCLI Flags:%s\n", bold, reset)
-	flagRows := make([]usageRow, 0, len(usageFlags))
-	for _, f := range usageFlags {
+func flagRows(flags []cliFlag) []usageRow {
+	rows := make([]usageRow, 0, len(flags))
+	for _, f := range flags {
 		desc := f.description
 		if f.defaultVal != "" {
 			desc += fmt.Sprintf(" %s(default: %s)%s", dim, f.defaultVal, reset)
 		}
-		flagRows = append(flagRows, usageRow{
+		rows = append(rows, usageRow{
 			label:      coloredFlagLabel(f),
 			plainLabel: plainFlagLabel(f),
 			desc:       desc,
 		})
 	}
-	printAlignedRows(flagRows)
-	fmt.Fprintln(os.Stderr)
+	return rows
+}
+
+func printCommandUsage(name string) {
+	cmd := findCommand(name)
+	if cmd == nil {
+		printUsage()
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%s🐦‍⬛ %schough %s%s — %s\n\n", bold, magenta, cmd.name, reset, cmd.summary)
 
-	fmt.Fprintf(os.Stderr, "%sServer Usage:%s\n", bold, reset)
-	fmt.Fprintln(os.Stderr, "  chough --server [flags]")
+	fmt.Fprintf(os.Stderr, "%sUsage:%s\n", bold, reset)
+	for _, u := range cmd.usage {
+		fmt.Fprintf(os.Stderr, "  %s\n", u)
+	}
+
+	if len(cmd.flags) > 0 {
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintf(os.Stderr, "%sFlags:%s\n", bold, reset)
+		printAlignedRows(flagRows(cmd.flags))
+	}
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "%s🐦‍⬛ %schough%s\n\n", bold, magenta, reset)
+
+	fmt.Fprintf(os.Stderr, "%sUsage:%s\n", bold, reset)
+	fmt.Fprintln(os.Stderr, "  chough <command> [flags]")
+	fmt.Fprintln(os.Stderr, "  chough [flags] [audio-file]")
+	fmt.Fprintln(os.Stderr, "  cat audio | chough [flags]")
 	fmt.Fprintln(os.Stderr)
 
-	fmt.Fprintf(os.Stderr, "%sServer Flags:%s\n", bold, reset)
-	serverRows := make([]usageRow, 0, len(serverFlags))
-	for _, f := range serverFlags {
-		desc := f.description
-		if f.defaultVal != "" {
-			desc += fmt.Sprintf(" %s(default: %s)%s", dim, f.defaultVal, reset)
-		}
-		serverRows = append(serverRows, usageRow{
-			label:      coloredFlagLabel(f),
-			plainLabel: plainFlagLabel(f),
-			desc:       desc,
+	fmt.Fprintf(os.Stderr, "%sCommands:%s\n", bold, reset)
+	commandRows := make([]usageRow, 0, len(commands))
+	for _, c := range commands {
+		commandRows = append(commandRows, usageRow{
+			label:      cyan + c.name + reset,
+			plainLabel: c.name,
+			desc:       c.summary,
 		})
 	}
-	printAlignedRows(serverRows)
+	printAlignedRows(commandRows)
+	fmt.Fprintln(os.Stderr)
+
+	fmt.Fprintf(os.Stderr, "%sTranscribe Flags:%s\n", bold, reset)
+	printAlignedRows(flagRows(usageFlags))
+	fmt.Fprintln(os.Stderr)
+
+	fmt.Fprintf(os.Stderr, "%sServe Flags:%s\n", bold, reset)
+	printAlignedRows(flagRows(serverFlags))
+	fmt.Fprintln(os.Stderr)
+
+	fmt.Fprintf(os.Stderr, "%sSubtitle Flags:%s %s(transcribe, remote, serve)%s\n", bold, reset, dim, reset)
+	printAlignedRows(flagRows(cueFlags))
+	fmt.Fprintln(os.Stderr)
+
+	fmt.Fprintf(os.Stderr, "%sASR Flags:%s %s(transcribe, serve)%s\n", bold, reset, dim, reset)
+	printAlignedRows(flagRows(asrFlags))
+	fmt.Fprintln(os.Stderr)
+
+	fmt.Fprintf(os.Stderr, "%sRemote ASR Flags:%s %s(remote)%s\n", bold, reset, dim, reset)
+	printAlignedRows(flagRows(remoteASRFlags))
 	fmt.Fprintln(os.Stderr)
 
 	fmt.Fprintf(os.Stderr, "%sExamples:%s\n", bold, reset)
 	exampleRows := []usageRow{
 		{label: fmt.Sprintf("%s$%s chough audio.mp3", green, reset), plainLabel: "$ chough audio.mp3", desc: fmt.Sprintf("%s# 60s chunks, text output%s", dim, reset)},
 		{label: fmt.Sprintf("%s$%s cat audio.mp3 | chough", green, reset), plainLabel: "$ cat audio.mp3 | chough", desc: fmt.Sprintf("%s# transcribe from pipe%s", dim, reset)},
+		{label: fmt.Sprintf("%s$%s chough https://example.com/episode.mp3", green, reset), plainLabel: "$ chough https://example.com/episode.mp3", desc: fmt.Sprintf("%s# download and transcribe%s", dim, reset)},
 		{label: fmt.Sprintf("%s$%s chough -c 30 talk.mp3", green, reset), plainLabel: "$ chough -c 30 talk.mp3", desc: fmt.Sprintf("%s# 30s chunks%s", dim, reset)},
-		{label: fmt.Sprintf("%s$%s CHOUGH_URL=http://localhost:8080 chough -r audio.mp3", green, reset), plainLabel: "$ CHOUGH_URL=http://localhost:8080 chough -r audio.mp3", desc: fmt.Sprintf("%s# transcribe via remote server%s", dim, reset)},
+		{label: fmt.Sprintf("%s$%s CHOUGH_URL=http://localhost:8080 chough remote audio.mp3", green, reset), plainLabel: "$ CHOUGH_URL=http://localhost:8080 chough remote audio.mp3", desc: fmt.Sprintf("%s# transcribe via remote server%s", dim, reset)},
 		{label: fmt.Sprintf("%s$%s chough -f vtt -o subs.vtt audio.mp3", green, reset), plainLabel: "$ chough -f vtt -o subs.vtt audio.mp3", desc: fmt.Sprintf("%s# WebVTT to file%s", dim, reset)},
-		{label: fmt.Sprintf("%s$%s chough --server --port 8080", green, reset), plainLabel: "$ chough --server --port 8080", desc: fmt.Sprintf("%s# Run server on port 8080%s", dim, reset)},
+		{label: fmt.Sprintf("%s$%s chough serve --port 8080", green, reset), plainLabel: "$ chough serve --port 8080", desc: fmt.Sprintf("%s# Run server on port 8080%s", dim, reset)},
+		{label: fmt.Sprintf("%s$%s chough config show", green, reset), plainLabel: "$ chough config show", desc: fmt.Sprintf("%s# Print effective settings%s", dim, reset)},
 	}
 	printAlignedRows(exampleRows)
 	fmt.Fprintln(os.Stderr)
@@ -268,6 +981,10 @@ func printUsage() {
 	envRows := []usageRow{
 		{label: fmt.Sprintf("%sCHOUGH_MODEL%s", cyan, reset), plainLabel: "CHOUGH_MODEL", desc: fmt.Sprintf("path to model dir %s(optional, auto-downloaded if not set)%s", dim, reset)},
 		{label: fmt.Sprintf("%sCHOUGH_URL%s", cyan, reset), plainLabel: "CHOUGH_URL", desc: fmt.Sprintf("remote server URL %s(required with --remote, must start with http:// or https://)%s", dim, reset)},
+		{label: fmt.Sprintf("%sCHOUGH_PUNCT_MODEL%s", cyan, reset), plainLabel: "CHOUGH_PUNCT_MODEL", desc: fmt.Sprintf("path to punctuation model dir %s(optional, auto-downloaded with --punctuate)%s", dim, reset)},
+		{label: fmt.Sprintf("%sCHOUGH_LID_MODEL%s", cyan, reset), plainLabel: "CHOUGH_LID_MODEL", desc: fmt.Sprintf("path to language identification model dir %s(optional, auto-downloaded with --detect-language)%s", dim, reset)},
+		{label: fmt.Sprintf("%sCHOUGH_VAD_MODEL%s", cyan, reset), plainLabel: "CHOUGH_VAD_MODEL", desc: fmt.Sprintf("path to VAD model dir %s(optional, auto-downloaded with --vad)%s", dim, reset)},
+		{label: fmt.Sprintf("%sCHOUGH_CONFIG%s", cyan, reset), plainLabel: "CHOUGH_CONFIG", desc: fmt.Sprintf("config file path %s(overridden by --config)%s", dim, reset)},
 	}
 	printAlignedRows(envRows)
 }
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	contents := "model = \"file-model\"\n\n[transcribe]\nchunk_size = 30\n\n[server]\nport = 9000\n"
+	if err := os.WriteFile(file, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		args      []string
+		model     string
+		chunkSize int
+		port      int
+	}{
+		{
+			name:      "defaults",
+			args:      []string{"config", "show"},
+			chunkSize: 60,
+			port:      8080,
+		},
+		{
+			name:      "file",
+			args:      []string{"config", "show", "--config", file},
+			model:     "file-model",
+			chunkSize: 30,
+			port:      9000,
+		},
+		{
+			name:      "file from env",
+			env:       map[string]string{"CHOUGH_CONFIG": file},
+			args:      []string{"config", "show"},
+			model:     "file-model",
+			chunkSize: 30,
+			port:      9000,
+		},
+		{
+			name:      "env over file",
+			env:       map[string]string{"CHOUGH_MODEL": "env-model"},
+			args:      []string{"config", "show", "--config", file},
+			model:     "env-model",
+			chunkSize: 30,
+			port:      9000,
+		},
+		{
+			name:      "flag over env",
+			env:       map[string]string{"CHOUGH_MODEL": "env-model"},
+			args:      []string{"config", "show", "transcribe", "--config", file, "--model", "flag-model", "-c", "10"},
+			model:     "flag-model",
+			chunkSize: 10,
+			port:      9000,
+		},
+		{
+			name:      "serve flags",
+			args:      []string{"config", "--config", file, "show", "serve", "--port", "9100"},
+			model:     "file-model",
+			chunkSize: 30,
+			port:      9100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Keep the user's config and environment out of the test
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			for _, key := range []string{"CHOUGH_CONFIG", "CHOUGH_MODEL", "CHOUGH_URL", "CHOUGH_PUNCT_MODEL", "CHOUGH_LID_MODEL", "CHOUGH_VAD_MODEL"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			opts, err := parseCLI(tt.args)
+			if err != nil {
+				t.Fatalf("parseCLI(%q) error = %v", tt.args, err)
+			}
+			cfg := opts.Config
+			if cfg.Model != tt.model || cfg.Transcribe.ChunkSize != tt.chunkSize || cfg.Server.Port != tt.port {
+				t.Errorf("model = %q, chunk size = %d, port = %d, want %q, %d, %d",
+					cfg.Model, cfg.Transcribe.ChunkSize, cfg.Server.Port, tt.model, tt.chunkSize, tt.port)
+			}
+		})
+	}
+}
+
+func TestConfigPathFromArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"audio.wav"}, ""},
+		{[]string{"--config", "a.toml", "audio.wav"}, "a.toml"},
+		{[]string{"-config", "a.toml"}, "a.toml"},
+		{[]string{"--config=a.toml"}, "a.toml"},
+		{[]string{"-c", "30", "audio.wav", "--config", "a.toml"}, "a.toml"},
+		{[]string{"--config"}, ""},
+		{[]string{"--configs", "a.toml"}, ""},
+		{[]string{"config", "a.toml"}, ""},
+		// Arguments after -- are not flags
+		{[]string{"--", "--config", "a.toml"}, ""},
+	}
+	for _, tt := range tests {
+		if got := configPathFromArgs(tt.args); got != tt.want {
+			t.Errorf("configPathFromArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		name string
+		rest []string
+	}{
+		{nil, "", nil},
+		{[]string{"audio.wav"}, "", []string{"audio.wav"}},
+		{[]string{"-c", "30", "audio.wav"}, "", []string{"-c", "30", "audio.wav"}},
+		{[]string{"transcribe", "audio.wav"}, "transcribe", []string{"audio.wav"}},
+		{[]string{"remote", "audio.wav"}, "remote", []string{"audio.wav"}},
+		{[]string{"serve", "--port", "9000"}, "serve", []string{"--port", "9000"}},
+		// Pre-subcommand flags map onto their commands
+		{[]string{"--server", "--port", "9000"}, "serve", []string{"--port", "9000"}},
+		{[]string{"-server"}, "serve", []string{}},
+		{[]string{"--version"}, "version", []string{}},
+		// Only the first argument names a command
+		{[]string{"audio.wav", "serve"}, "", []string{"audio.wav", "serve"}},
+	}
+	for _, tt := range tests {
+		name, rest := splitCommand(tt.args)
+		if name != tt.name || strings.Join(rest, " ") != strings.Join(tt.rest, " ") {
+			t.Errorf("splitCommand(%q) = %q, %q, want %q, %q", tt.args, name, rest, tt.name, tt.rest)
+		}
+	}
+}
+
+func TestParseRemoteDecoding(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("CHOUGH_CONFIG", "")
+
+	opts, err := parseCLI([]string{"remote", "audio.wav"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := opts.RemoteDecoding; d.Method != "" || d.MaxActivePaths != 0 || d.BlankPenalty != nil || d.Hotwords != nil {
+		t.Errorf("RemoteDecoding = %+v, want the server's settings", d)
+	}
+
+	opts, err = parseCLI([]string{"remote", "--decoding-method", "modified_beam_search", "--blank-penalty", "0",
+		"--hotwords", "chough, sherpa onnx", "--hotwords", "parakeet", "audio.wav"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := opts.RemoteDecoding
+	if d.Method != "modified_beam_search" || d.MaxActivePaths != 0 || d.BlankPenalty == nil || *d.BlankPenalty != 0 {
+		t.Errorf("RemoteDecoding = %+v, want the method and a zero blank penalty", d)
+	}
+	if strings.Join(d.Hotwords, "|") != "chough|sherpa onnx|parakeet" {
+		t.Errorf("Hotwords = %q", d.Hotwords)
+	}
+
+	// The transcribe command forwards the decoding flags in remote mode too
+	opts, err = parseCLI([]string{"-r", "--max-active-paths", "8", "audio.wav"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := opts.RemoteDecoding; d.MaxActivePaths != 8 || d.Method != "" {
+		t.Errorf("RemoteDecoding = %+v, want only max active paths", d)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hyperpuncher/chough/internal/asr"
+	"github.com/hyperpuncher/chough/internal/config"
+	"github.com/hyperpuncher/chough/internal/output"
+	"github.com/hyperpuncher/chough/internal/server"
+)
+
+// runConfig handles `chough config show`
+func runConfig(opts *cliOptions) error {
+	cfg := opts.Config
+	if cfg.Path != "" {
+		fmt.Fprintf(os.Stderr, "%s# loaded from %s%s\n", dim, cfg.Path, reset)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s# no config file (looked for %s)%s\n", dim, config.DefaultPath(), reset)
+	}
+	return cfg.Write(os.Stdout)
+}
+
+// loadConfig loads the config file and environment over the built-in
+// defaults
+func loadConfig(path string) (*config.Config, error) {
+	return config.Load(path, defaultConfig())
+}
+
+// defaultConfig returns the built-in defaults in config file form
+func defaultConfig() *config.Config {
+	asrDefaults := asr.DefaultConfig("")
+	serverDefaults := server.DefaultServerOptions()
+	textDefaults := output.DefaultTextOptions()
+
+	return &config.Config{
+		Transcribe: config.TranscribeConfig{
+			ChunkSize:     60,
+			Format:        "text",
+			LowConfidence: output.LowConfidenceMark,
+			Task:          asrDefaults.Task,
+			ParagraphGap:  textDefaults.ParagraphGap,
+			FrameRate:     textDefaults.FrameRate,
+			Preprocess:    "none",
+			MaxDownload:   1024,
+		},
+		ASR: config.ASRConfig{
+			Threads:        asrDefaults.NumThreads,
+			Provider:       asrDefaults.Provider,
+			DecodingMethod: asrDefaults.DecodingMethod,
+			MaxActivePaths: asrDefaults.MaxActivePaths,
+			BlankPenalty:   float64(asrDefaults.BlankPenalty),
+			HotwordsScore:  float64(asrDefaults.HotwordsScore),
+		},
+		Subtitles: subtitlesConfig(output.DefaultCueOptions()),
+		Server: config.ServerConfig{
+			Host:      serverDefaults.Host,
+			Port:      serverDefaults.Port,
+			Workers:   serverDefaults.Workers,
+			MaxUpload: int(serverDefaults.MaxUploadMB),
+
+			URLSchemes: serverDefaults.URLPolicy.Schemes,
+			URLAllow:   []string{},
+			URLDeny:    []string{},
+		},
+	}
+}
+
+// subtitlesConfig converts cue rules to their config file form
+func subtitlesConfig(cues output.CueOptions) config.SubtitlesConfig {
+	return config.SubtitlesConfig{
+		MaxCueDuration:    cues.MaxDuration,
+		MinCueDuration:    cues.MinDuration,
+		MaxLineChars:      cues.MaxLineChars,
+		MaxLines:          cues.MaxLines,
+		MaxCPS:            cues.MaxCPS,
+		MinCueGap:         cues.MinGap,
+		SplitOnComma:      cues.SplitOnComma,
+		SplitOnPause:      cues.SplitOnPause,
+		CuePadding:        cues.Padding,
+		ExtendIntoSilence: cues.ExtendIntoSilence,
+	}
+}
+
+// cueOptions converts the config file's subtitle section to cue rules
+func cueOptions(cfg config.SubtitlesConfig) output.CueOptions {
+	return output.CueOptions{
+		MaxDuration:       cfg.MaxCueDuration,
+		MinDuration:       cfg.MinCueDuration,
+		MaxLineChars:      cfg.MaxLineChars,
+		MaxLines:          cfg.MaxLines,
+		MaxCPS:            cfg.MaxCPS,
+		MinGap:            cfg.MinCueGap,
+		SplitOnComma:      cfg.SplitOnComma,
+		SplitOnPause:      cfg.SplitOnPause,
+		Padding:           cfg.CuePadding,
+		ExtendIntoSilence: cfg.ExtendIntoSilence,
+	}
+}
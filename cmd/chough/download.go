@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// errDownloadTooLarge is returned once a download passes --max-download
+var errDownloadTooLarge = errors.New("download too large")
+
+// isURL reports whether the input names an http(s) URL rather than a file
+func isURL(input string) bool {
+	u, err := url.Parse(input)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return scheme == "http" || scheme == "https"
+}
+
+// downloadToTemp streams rawURL to a temporary file, showing progress and
+// stopping at maxMB. The file keeps the URL's extension so ffmpeg can tell
+// the format. Returns the path to the temp file which the caller must clean up.
+func downloadToTemp(rawURL string, maxMB int) (string, error) {
+	limit := int64(maxMB) * 1024 * 1024
+
+	resp, err := http.Get(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download failed: %s", resp.Status)
+	}
+	if resp.ContentLength > limit {
+		return "", fmt.Errorf("%w: %.1f MB (max %d MB, see --max-download)", errDownloadTooLarge, mb(resp.ContentLength), maxMB)
+	}
+
+	ext := ""
+	if u, err := url.Parse(rawURL); err == nil {
+		ext = path.Ext(u.Path)
+	}
+	tmpFile, err := os.CreateTemp("", "chough-url-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	hideCursor()
+	defer showCursor()
+
+	progress := &downloadProgress{total: resp.ContentLength}
+	written, err := io.Copy(io.MultiWriter(tmpFile, progress), io.LimitReader(resp.Body, limit+1))
+	fmt.Fprintln(os.Stderr)
+	if err == nil && written > limit {
+		err = fmt.Errorf("%w: more than %d MB (see --max-download)", errDownloadTooLarge, maxMB)
+	}
+	if err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		if !errors.Is(err, errDownloadTooLarge) {
+			err = fmt.Errorf("failed to download: %w", err)
+		}
+		return "", err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("failed to close file: %w", err)
+	}
+	return tmpFile.Name(), nil
+}
+
+// downloadProgress prints a single-line progress report as bytes arrive,
+// as a percentage if the size is known and every megabyte otherwise
+type downloadProgress struct {
+	total   int64
+	written int64
+	last    int64
+}
+
+func (p *downloadProgress) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+
+	step := p.written / (1024 * 1024)
+	if p.total > 0 {
+		step = p.written * 100 / p.total
+	}
+	if step != p.last || p.written == int64(len(b)) {
+		p.last = step
+		if p.total > 0 {
+			fmt.Fprintf(os.Stderr, "\r⏳ Downloading: %.1f / %.1f MB (%d%%)", mb(p.written), mb(p.total), step)
+		} else {
+			fmt.Fprintf(os.Stderr, "\r⏳ Downloading: %.1f MB", mb(p.written))
+		}
+	}
+	return len(b), nil
+}
+
+// mb converts a byte count to megabytes
+func mb(n int64) float64 {
+	return float64(n) / (1024 * 1024)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hyperpuncher/chough/internal/models"
+)
+
+// runModels handles `chough models [list|path|download]`
+func runModels(opts *cliOptions) error {
+	switch opts.ModelsAction {
+	case "download":
+		modelPath, _, err := models.ResolveASR(opts.Model)
+		if err != nil {
+			return fmt.Errorf("failed to get model: %w", err)
+		}
+		fmt.Println(modelPath)
+		return nil
+	case "path":
+		fmt.Println(resolveModelDir(opts.Model))
+		return nil
+	default:
+		for _, spec := range models.Registry {
+			modelDir := spec.CacheDir()
+			if current, ok := models.Lookup(opts.Model); ok && current.Name == spec.Name {
+				modelDir = resolveSpecDir(spec, opts.Model)
+			}
+			printModel(spec, modelDir)
+			fmt.Fprintln(os.Stdout)
+		}
+		printModel(models.Punctuation, resolveSpecDir(models.Punctuation, opts.PunctuationModel))
+		fmt.Fprintln(os.Stdout)
+		printModel(models.LanguageID, resolveSpecDir(models.LanguageID, opts.LanguageIDModel))
+		fmt.Fprintln(os.Stdout)
+		printModel(models.VAD, resolveSpecDir(models.VAD, opts.VADModel))
+		return nil
+	}
+}
+
+func printModel(spec models.Spec, modelDir string) {
+	status := yellow + "not downloaded" + reset
+	if spec.IsValid(modelDir) {
+		status = green + "ready" + reset
+	}
+	fmt.Fprintf(os.Stdout, "%s%s%s\n", bold, spec.Name, reset)
+	fmt.Fprintf(os.Stdout, "  path:   %s\n", modelDir)
+	fmt.Fprintf(os.Stdout, "  status: %s\n", status)
+	fmt.Fprintf(os.Stdout, "  source: %s\n", spec.URL)
+	if spec.Translate {
+		fmt.Fprintf(os.Stdout, "  tasks:  transcribe translate\n")
+	}
+	if len(spec.Languages) > 0 {
+		fmt.Fprintf(os.Stdout, "  langs:  %s\n", strings.Join(spec.Languages, " "))
+	}
+}
+
+// resolveModelDir returns the configured model dir if valid, else the cache
+// location of the registry model it names (the default if none)
+func resolveModelDir(modelPath string) string {
+	spec, ok := models.Lookup(modelPath)
+	if !ok {
+		if models.DetectKind(modelPath) != "" {
+			return modelPath
+		}
+		spec = models.ASR
+	}
+	return resolveSpecDir(spec, modelPath)
+}
+
+func resolveSpecDir(spec models.Spec, modelPath string) string {
+	if modelPath != "" && spec.IsValid(modelPath) {
+		return modelPath
+	}
+	return spec.CacheDir()
+}
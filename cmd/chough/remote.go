@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -10,8 +11,13 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/hyperpuncher/chough/internal/asr"
+	"github.com/hyperpuncher/chough/internal/audio"
+	"github.com/hyperpuncher/chough/internal/output"
+	"github.com/hyperpuncher/chough/internal/server"
 	"github.com/hyperpuncher/chough/internal/types"
 )
 
@@ -20,13 +26,23 @@ type remoteJSONResponse struct {
 	Error    string              `json:"error,omitempty"`
 	Duration float64             `json:"duration_seconds"`
 	Text     string              `json:"text"`
+	Metadata *types.Metadata     `json:"metadata,omitempty"`
 	Chunks   []types.ChunkResult `json:"chunks,omitempty"`
 }
 
-func resolveRemoteURL() (string, error) {
-	raw := strings.TrimSpace(os.Getenv("CHOUGH_URL"))
+// remoteDecoding holds the decoding settings sent with a remote request.
+// Unset fields keep the server's settings.
+type remoteDecoding struct {
+	Method         string
+	MaxActivePaths int
+	BlankPenalty   *float64
+	Hotwords       []string
+}
+
+func resolveRemoteURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
 	if raw == "" {
-		return "", fmt.Errorf("--remote requires CHOUGH_URL (e.g. CHOUGH_URL=http://localhost:8080)")
+		return "", fmt.Errorf("--remote requires CHOUGH_URL or url in config (e.g. CHOUGH_URL=http://localhost:8080)")
 	}
 
 	u, err := url.Parse(raw)
@@ -43,74 +59,278 @@ func resolveRemoteURL() (string, error) {
 	return strings.TrimRight(raw, "/"), nil
 }
 
-func transcribeRemote(serverURL, audioFile string, chunkSize int) ([]types.ChunkResult, float64, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	fileWriter, err := writer.CreateFormFile("file", filepath.Base(audioFile))
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to create multipart file field: %w", err)
+// transcribeRemote sends audioFile to the server, forwarding the
+// transcription options. Files are uploaded; http(s) URLs are passed on for
+// the server to download. The returned response always carries the
+// transcript in Chunks. If onChunk is set the server streams jsonl and
+// onChunk is called for each chunk as it arrives.
+func transcribeRemote(serverURL, audioFile string, opts *cliOptions, onChunk func(types.ChunkResult) error) (*remoteJSONResponse, error) {
+	format := "json"
+	if onChunk != nil {
+		format = "jsonl"
 	}
 
-	file, err := os.Open(audioFile)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to open audio file: %w", err)
+	var (
+		body        *bytes.Buffer
+		contentType string
+		err         error
+	)
+	if isURL(audioFile) {
+		body, err = remoteJSONBody(audioFile, format, opts)
+		contentType = "application/json"
+	} else {
+		body, contentType, err = remoteMultipartBody(audioFile, format, opts)
 	}
-	defer file.Close()
-
-	if _, err := io.Copy(fileWriter, file); err != nil {
-		return nil, 0, fmt.Errorf("failed to stream audio file: %w", err)
-	}
-
-	if err := writer.WriteField("format", "json"); err != nil {
-		return nil, 0, fmt.Errorf("failed to set format: %w", err)
-	}
-	if err := writer.WriteField("chunk_size", fmt.Sprintf("%d", chunkSize)); err != nil {
-		return nil, 0, fmt.Errorf("failed to set chunk_size: %w", err)
-	}
-	if err := writer.Close(); err != nil {
-		return nil, 0, fmt.Errorf("failed to finalize multipart body: %w", err)
+	if err != nil {
+		return nil, err
 	}
 
 	endpoint := serverURL + "/transcribe"
 	req, err := http.NewRequest(http.MethodPost, endpoint, body)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Content-Type", contentType)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, 0, fmt.Errorf("remote request failed to %s: %w", endpoint, err)
+		return nil, fmt.Errorf("remote request failed to %s: %w", endpoint, err)
 	}
 	defer resp.Body.Close()
 
+	if onChunk != nil && resp.StatusCode == http.StatusOK {
+		return readRemoteStream(resp.Body, onChunk)
+	}
+
 	var parsed remoteJSONResponse
 	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
-		return nil, 0, fmt.Errorf("failed to decode remote JSON response: %w", err)
+		return nil, fmt.Errorf("failed to decode remote JSON response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		if parsed.Error != "" {
-			return nil, 0, fmt.Errorf("remote server returned %s: %s", resp.Status, parsed.Error)
+			return nil, fmt.Errorf("remote server returned %s: %s", resp.Status, parsed.Error)
 		}
-		return nil, 0, fmt.Errorf("remote server returned %s", resp.Status)
+		return nil, fmt.Errorf("remote server returned %s", resp.Status)
 	}
 
 	if !parsed.Success {
 		if parsed.Error != "" {
-			return nil, 0, fmt.Errorf("remote transcription failed: %s", parsed.Error)
+			return nil, fmt.Errorf("remote transcription failed: %s", parsed.Error)
+		}
+		return nil, fmt.Errorf("remote transcription failed")
+	}
+
+	if len(parsed.Chunks) == 0 {
+		parsed.Chunks = []types.ChunkResult{}
+		if strings.TrimSpace(parsed.Text) != "" {
+			parsed.Chunks = append(parsed.Chunks, types.ChunkResult{Text: parsed.Text})
 		}
-		return nil, 0, fmt.Errorf("remote transcription failed")
 	}
 
-	if len(parsed.Chunks) > 0 {
-		return parsed.Chunks, parsed.Duration, nil
+	return &parsed, nil
+}
+
+// remoteMultipartBody builds a multipart upload of audioFile with the
+// transcription options as form fields
+func remoteMultipartBody(audioFile, format string, opts *cliOptions) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	fileWriter, err := writer.CreateFormFile("file", filepath.Base(audioFile))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create multipart file field: %w", err)
 	}
 
-	if strings.TrimSpace(parsed.Text) == "" {
-		return []types.ChunkResult{}, parsed.Duration, nil
+	file, err := os.Open(audioFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open audio file: %w", err)
 	}
+	defer file.Close()
 
-	return []types.ChunkResult{{Text: parsed.Text}}, parsed.Duration, nil
+	if _, err := io.Copy(fileWriter, file); err != nil {
+		return nil, "", fmt.Errorf("failed to stream audio file: %w", err)
+	}
+
+	if err := writer.WriteField("format", format); err != nil {
+		return nil, "", fmt.Errorf("failed to set format: %w", err)
+	}
+	if err := writer.WriteField("chunk_size", fmt.Sprintf("%d", opts.ChunkSize)); err != nil {
+		return nil, "", fmt.Errorf("failed to set chunk_size: %w", err)
+	}
+	if opts.Punctuate {
+		if err := writer.WriteField("punctuate", "true"); err != nil {
+			return nil, "", fmt.Errorf("failed to set punctuate: %w", err)
+		}
+	}
+	if opts.ITN {
+		if err := writer.WriteField("itn", "true"); err != nil {
+			return nil, "", fmt.Errorf("failed to set itn: %w", err)
+		}
+	}
+	if opts.Task != asr.TaskTranscribe {
+		if err := writer.WriteField("task", opts.Task); err != nil {
+			return nil, "", fmt.Errorf("failed to set task: %w", err)
+		}
+	}
+	if opts.AudioStream > 0 {
+		if err := writer.WriteField("audio_stream", strconv.Itoa(opts.AudioStream)); err != nil {
+			return nil, "", fmt.Errorf("failed to set audio_stream: %w", err)
+		}
+	}
+	if opts.Channel != audio.DownmixChannels {
+		if err := writer.WriteField("channel", strconv.Itoa(opts.Channel+1)); err != nil {
+			return nil, "", fmt.Errorf("failed to set channel: %w", err)
+		}
+	}
+	if opts.SplitChannels {
+		if err := writer.WriteField("split_channels", "true"); err != nil {
+			return nil, "", fmt.Errorf("failed to set split_channels: %w", err)
+		}
+	}
+	// The preset is resolved locally, so send the filters it expands to
+	if opts.Filters.HighPass > 0 {
+		if err := writer.WriteField("highpass", strconv.FormatFloat(opts.Filters.HighPass, 'f', -1, 64)); err != nil {
+			return nil, "", fmt.Errorf("failed to set highpass: %w", err)
+		}
+	}
+	if opts.Filters.Denoise {
+		if err := writer.WriteField("denoise", "true"); err != nil {
+			return nil, "", fmt.Errorf("failed to set denoise: %w", err)
+		}
+	}
+	if opts.Filters.Loudnorm {
+		if err := writer.WriteField("loudnorm", "true"); err != nil {
+			return nil, "", fmt.Errorf("failed to set loudnorm: %w", err)
+		}
+	}
+	if opts.Language != "" {
+		if err := writer.WriteField("language", opts.Language); err != nil {
+			return nil, "", fmt.Errorf("failed to set language: %w", err)
+		}
+	}
+	if opts.DetectLanguage {
+		if err := writer.WriteField("detect_language", "true"); err != nil {
+			return nil, "", fmt.Errorf("failed to set detect_language: %w", err)
+		}
+	}
+	if opts.VAD {
+		if err := writer.WriteField("vad", "true"); err != nil {
+			return nil, "", fmt.Errorf("failed to set vad: %w", err)
+		}
+	}
+	decoding := opts.RemoteDecoding
+	if decoding.Method != "" {
+		if err := writer.WriteField("decoding_method", decoding.Method); err != nil {
+			return nil, "", fmt.Errorf("failed to set decoding_method: %w", err)
+		}
+	}
+	if decoding.MaxActivePaths > 0 {
+		if err := writer.WriteField("max_active_paths", strconv.Itoa(decoding.MaxActivePaths)); err != nil {
+			return nil, "", fmt.Errorf("failed to set max_active_paths: %w", err)
+		}
+	}
+	if decoding.BlankPenalty != nil {
+		if err := writer.WriteField("blank_penalty", strconv.FormatFloat(*decoding.BlankPenalty, 'f', -1, 64)); err != nil {
+			return nil, "", fmt.Errorf("failed to set blank_penalty: %w", err)
+		}
+	}
+	for _, h := range decoding.Hotwords {
+		if err := writer.WriteField("hotwords", h); err != nil {
+			return nil, "", fmt.Errorf("failed to set hotwords: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to finalize multipart body: %w", err)
+	}
+	return body, writer.FormDataContentType(), nil
+}
+
+// remoteJSONBody builds a JSON request asking the server to download
+// audioURL, with the same options remoteMultipartBody sends
+func remoteJSONBody(audioURL, format string, opts *cliOptions) (*bytes.Buffer, error) {
+	req := server.TranscribeRequest{
+		URL:            audioURL,
+		Format:         format,
+		ChunkSize:      opts.ChunkSize,
+		Punctuate:      opts.Punctuate,
+		ITN:            opts.ITN,
+		Language:       opts.Language,
+		DetectLanguage: opts.DetectLanguage,
+		VAD:            opts.VAD,
+		AudioStream:    opts.AudioStream,
+		SplitChannels:  opts.SplitChannels,
+		// The preset is resolved locally, so send the filters it expands to
+		HighPass: opts.Filters.HighPass,
+		Denoise:  opts.Filters.Denoise,
+		Loudnorm: opts.Filters.Loudnorm,
+
+		DecodingMethod: opts.RemoteDecoding.Method,
+		MaxActivePaths: opts.RemoteDecoding.MaxActivePaths,
+		BlankPenalty:   opts.RemoteDecoding.BlankPenalty,
+		Hotwords:       opts.RemoteDecoding.Hotwords,
+	}
+	if opts.Task != asr.TaskTranscribe {
+		req.Task = opts.Task
+	}
+	if opts.Channel != audio.DownmixChannels {
+		req.Channel = strconv.Itoa(opts.Channel + 1)
+	}
+
+	body := &bytes.Buffer{}
+	if err := json.NewEncoder(body).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	return body, nil
+}
+
+// readRemoteStream reads a jsonl response, passing chunks to onChunk
+func readRemoteStream(body io.Reader, onChunk func(types.ChunkResult) error) (*remoteJSONResponse, error) {
+	parsed := &remoteJSONResponse{Chunks: []types.ChunkResult{}}
+	dec := json.NewDecoder(body)
+
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("remote stream ended without a summary")
+			}
+			return nil, fmt.Errorf("failed to decode remote stream: %w", err)
+		}
+
+		var line struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &line); err != nil {
+			return nil, fmt.Errorf("failed to decode remote stream: %w", err)
+		}
+
+		switch line.Type {
+		case output.JSONLChunk:
+			var chunk output.JSONLChunkLine
+			if err := json.Unmarshal(raw, &chunk); err != nil {
+				return nil, fmt.Errorf("failed to decode remote chunk: %w", err)
+			}
+			parsed.Chunks = append(parsed.Chunks, chunk.ChunkResult)
+			if err := onChunk(chunk.ChunkResult); err != nil {
+				return nil, err
+			}
+		case output.JSONLSummary:
+			var summary output.JSONLSummaryLine
+			if err := json.Unmarshal(raw, &summary); err != nil {
+				return nil, fmt.Errorf("failed to decode remote summary: %w", err)
+			}
+			parsed.Success = true
+			parsed.Duration = summary.Duration
+			parsed.Text = summary.Text
+			parsed.Metadata = summary.Metadata
+			return parsed, nil
+		case output.JSONLError:
+			var e output.JSONLErrorLine
+			if err := json.Unmarshal(raw, &e); err != nil {
+				return nil, fmt.Errorf("failed to decode remote error: %w", err)
+			}
+			return nil, fmt.Errorf("remote transcription failed: %s", e.Error)
+		}
+	}
 }
@@ -5,13 +5,13 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/hyperpuncher/chough/internal/asr"
 	"github.com/hyperpuncher/chough/internal/audio"
 	"github.com/hyperpuncher/chough/internal/models"
 	"github.com/hyperpuncher/chough/internal/output"
+	"github.com/hyperpuncher/chough/internal/pipeline"
 	"github.com/hyperpuncher/chough/internal/types"
 )
 
@@ -22,42 +22,76 @@ func run(args []string) error {
 		case errors.Is(err, errShowHelp):
 			return nil
 		case errors.Is(err, errInvalidArgs):
-			printUsage()
+			printCommandUsage(opts.Command)
 			return err
 		default:
 			return err
 		}
 	}
 
-	if opts.ShowVersion {
+	switch opts.Command {
+	case "version":
 		fmt.Println(version)
 		return nil
-	}
-
-	// Server mode
-	if opts.ServerMode {
+	case "serve":
 		return runServer(&opts)
+	case "models":
+		return runModels(&opts)
+	case "config":
+		return runConfig(&opts)
+	default:
+		return runTranscribe(&opts)
 	}
+}
 
-	// Handle stdin input by copying to temp file
+func runTranscribe(opts *cliOptions) (err error) {
+	// Handle stdin input by copying to temp file. URLs are downloaded
+	// locally, but a remote server fetches them itself.
 	audioFile := opts.AudioFile
-	var tempFile string
 	if opts.AudioFile == "-" {
-		tempFile, err = copyStdinToTemp()
+		tempFile, err := copyStdinToTemp()
 		if err != nil {
 			return fmt.Errorf("failed to read stdin: %w", err)
 		}
 		audioFile = tempFile
 		defer os.Remove(tempFile)
+	} else if isURL(opts.AudioFile) && !opts.RemoteMode {
+		tempFile, err := downloadToTemp(opts.AudioFile, opts.MaxDownload)
+		if err != nil {
+			return err
+		}
+		audioFile = tempFile
+		defer os.Remove(tempFile)
 	}
 
 	var (
 		results  []types.ChunkResult
 		duration float64
+		meta     *types.Metadata
 	)
 
+	// jsonl is written as chunks are transcribed, so open the output first
+	var stream *output.JSONLWriter
+	var onChunk func(types.ChunkResult) error
+	if opts.Format == "jsonl" {
+		out, closeFn, openErr := openOutput(opts.OutputFile)
+		if openErr != nil {
+			return openErr
+		}
+		defer closeFn()
+		stream = output.NewJSONLWriter(out)
+		onChunk = stream.WriteChunk
+
+		// Whatever fails from here on is reported in the stream as well
+		defer func() {
+			if err != nil {
+				stream.WriteError(err)
+			}
+		}()
+	}
+
 	if opts.RemoteMode {
-		serverURL, err := resolveRemoteURL()
+		serverURL, err := resolveRemoteURL(opts.RemoteURL)
 		if err != nil {
 			return err
 		}
@@ -68,64 +102,151 @@ func run(args []string) error {
 		}
 		fmt.Fprintf(os.Stderr, "audio: %s %s•%s chunks: %ds %s•%s format: %s\n", srcInfo, dim, reset, opts.ChunkSize, dim, reset, opts.Format)
 
-		results, duration, err = transcribeRemote(serverURL, audioFile, opts.ChunkSize)
+		resp, err := transcribeRemote(serverURL, audioFile, opts, onChunk)
 		if err != nil {
 			return err
 		}
+		results, duration, meta = resp.Chunks, resp.Duration, resp.Metadata
 	} else {
 		fmt.Fprintf(os.Stderr, "mode: %slocal%s\n", cyan, reset)
 
-		recognizer, err := loadRecognizer()
+		// Reject unusable input before loading any model
+		probe, err := audio.ProbeFile(audioFile)
+		if err != nil {
+			return fmt.Errorf("failed to probe audio: %w", err)
+		}
+		input, err := probe.Stream(opts.AudioStream)
 		if err != nil {
 			return err
 		}
-		defer recognizer.Close()
+		tracks, err := audio.Tracks(input, opts.Channel, opts.SplitChannels)
+		if err != nil {
+			return err
+		}
+		duration = input.Duration
 
-		duration, err = audio.ProbeDuration(audioFile)
+		recognizer, err := loadRecognizer(opts.asrConfig())
 		if err != nil {
-			return fmt.Errorf("failed to get duration: %w", err)
+			return err
+		}
+		defer recognizer.Close()
+		meta = &types.Metadata{ASR: recognizer.Config.Settings()}
+
+		if limit := recognizer.Config.MaxChunkSize(); limit > 0 && opts.ChunkSize > limit {
+			fmt.Fprintf(os.Stderr, "%snote: %s decodes at most %ds, using %ds chunks%s\n", dim, meta.ASR.Model, limit, limit, reset)
+			opts.ChunkSize = limit
 		}
 
-		boundaries := audio.BuildBoundaries(duration, opts.ChunkSize)
-		fmt.Fprintf(os.Stderr, "audio: %.1fs %s•%s chunks: %ds %s•%s format: %s\n",
-			duration, dim, reset, opts.ChunkSize, dim, reset, opts.Format)
+		var punctuator *asr.Punctuator
+		if opts.Punctuate {
+			punctuator, err = loadPunctuator(opts.PunctuationModel, opts.Provider)
+			if err != nil {
+				return err
+			}
+			defer punctuator.Close()
+			meta.Punctuation = punctuator.Name
+		}
+
+		streamOpts := asr.StreamOptions{Language: opts.Language}
+		if opts.DetectLanguage && opts.Language == "" {
+			identifier, err := loadLanguageIdentifier(opts.LanguageIDModel, opts.Provider)
+			if err != nil {
+				return err
+			}
+			defer identifier.Close()
+			streamOpts.LanguageID = identifier
+			meta.LanguageID = identifier.Name
+		}
+		if opts.VAD {
+			vad, err := loadVAD(opts.VADModel, opts.Provider)
+			if err != nil {
+				return err
+			}
+			defer vad.Close()
+			streamOpts.VAD = vad
+		}
 
-		var elapsed time.Duration
-		results, elapsed = transcribeAudio(recognizer, audioFile, boundaries)
+		boundaries := audio.BuildBoundaries(duration, opts.ChunkSize)
+		fmt.Fprintf(os.Stderr, "audio: %.1fs %s %s %s•%s chunks: %ds %s•%s format: %s\n",
+			duration, probe.Container, input, dim, reset, opts.ChunkSize, dim, reset, opts.Format)
+
+		meta.ITN = opts.ITN
+		meta.Preprocess = opts.Filters.String()
+
+		var (
+			elapsed time.Duration
+			speech  []types.Span
+		)
+		results, speech, elapsed, err = transcribeAudio(audioFile, boundaries, pipeline.Options{
+			Recognizer: recognizer,
+			Punctuator: punctuator,
+			ITN:        opts.ITN,
+			Tracks:     tracks,
+			Chunk:      audio.ChunkOptions{Stream: input.Index, Filters: opts.Filters},
+			Stream:     streamOpts,
+			OnResult:   onChunk,
+		})
+		if err != nil {
+			return err
+		}
 
+		// The realtime factor is over the whole file, so it includes the
+		// time VAD saved by skipping non-speech
 		rtFactor := duration / elapsed.Seconds()
 		rtColor := green
 		if rtFactor < 10 {
 			rtColor = yellow
 		}
-		fmt.Fprintf(os.Stderr, "%s⚡%s Processed in %s%.1fs%s %s(%s%.1fx%s realtime)%s\n\n",
-			yellow, reset, bold, elapsed.Seconds(), reset, dim, rtColor, rtFactor, reset, reset)
+		skipped := ""
+		if streamOpts.VAD != nil {
+			meta.VAD = types.NewVADReport(streamOpts.VAD.Name, speech, duration)
+			skipped = fmt.Sprintf(", %.1fs of non-speech skipped", meta.VAD.Skipped)
+		}
+		fmt.Fprintf(os.Stderr, "%s⚡%s Processed in %s%.1fs%s %s(%s%.1fx%s realtime%s)%s\n\n",
+			yellow, reset, bold, elapsed.Seconds(), reset, dim, rtColor, rtFactor, reset, skipped, reset)
 	}
 
+	if stream != nil {
+		if err := stream.WriteSummary(output.JSONLSummaryLine{Duration: duration, Metadata: meta}); err != nil {
+			return fmt.Errorf("error writing output: %w", err)
+		}
+	} else if err := writeOutput(opts, results, duration, meta); err != nil {
+		return err
+	}
+
+	if opts.EmbedSubs != "" {
+		return embedSubtitles(opts, audioFile, results, meta)
+	}
+	return nil
+}
+
+func writeOutput(opts *cliOptions, results []types.ChunkResult, duration float64, meta *types.Metadata) error {
 	out, closeFn, err := openOutput(opts.OutputFile)
 	if err != nil {
 		return err
 	}
 	defer closeFn()
 
-	if err := output.Write(out, opts.Format, results, duration); err != nil {
+	if err := output.Write(out, opts.Format, results, duration, opts.outputOptions(meta)); err != nil {
 		return fmt.Errorf("error writing output: %w", err)
 	}
 	return nil
 }
 
-func loadRecognizer() (*asr.Recognizer, error) {
+func loadRecognizer(cfg *asr.Config) (*asr.Recognizer, error) {
 	hideCursor()
 	defer showCursor()
 
 	fmt.Fprint(os.Stderr, "⏳ Loading model...\r")
-	modelPath, err := models.GetModelPath()
+	modelPath, spec, err := models.ResolveASR(cfg.ModelPath)
 	if err != nil {
 		fmt.Fprintln(os.Stderr)
 		return nil, fmt.Errorf("failed to get model: %w", err)
 	}
+	cfg.ModelPath = modelPath
+	cfg.Kind = spec.Kind
 
-	recognizer, err := asr.NewRecognizer(asr.DefaultConfig(modelPath))
+	recognizer, err := asr.NewRecognizer(cfg)
 	if err != nil {
 		fmt.Fprintln(os.Stderr)
 		return nil, fmt.Errorf("failed to load model: %w", err)
@@ -135,40 +256,108 @@ func loadRecognizer() (*asr.Recognizer, error) {
 	return recognizer, nil
 }
 
-func transcribeAudio(recognizer *asr.Recognizer, audioFile string, boundaries []float64) ([]types.ChunkResult, time.Duration) {
-	startTime := time.Now()
-	results := make([]types.ChunkResult, 0, len(boundaries)-1)
-	total := len(boundaries) - 1
+func loadLanguageIdentifier(modelPath, provider string) (*asr.LanguageIdentifier, error) {
+	hideCursor()
+	defer showCursor()
+
+	fmt.Fprint(os.Stderr, "⏳ Loading language identification model...\r")
+	modelPath, err := models.Resolve(models.LanguageID, modelPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		return nil, fmt.Errorf("failed to get language identification model: %w", err)
+	}
+
+	identifier, err := asr.NewLanguageIdentifier(modelPath, provider)
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		return nil, fmt.Errorf("failed to load language identification model: %w", err)
+	}
+
+	fmt.Fprintln(os.Stderr, "✅ Language identification model loaded!   ")
+	return identifier, nil
+}
 
+func loadVAD(modelPath, provider string) (*asr.VoiceActivityDetector, error) {
 	hideCursor()
 	defer showCursor()
 
-	for i := 0; i < total; i++ {
-		chunkStart := boundaries[i]
-		chunkEnd := boundaries[i+1]
+	fmt.Fprint(os.Stderr, "⏳ Loading VAD model...\r")
+	modelPath, err := models.Resolve(models.VAD, modelPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		return nil, fmt.Errorf("failed to get VAD model: %w", err)
+	}
+
+	vad, err := asr.NewVoiceActivityDetector(modelPath, provider)
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		return nil, fmt.Errorf("failed to load VAD model: %w", err)
+	}
+
+	fmt.Fprintln(os.Stderr, "✅ VAD model loaded!   ")
+	return vad, nil
+}
+
+func loadPunctuator(modelPath, provider string) (*asr.Punctuator, error) {
+	hideCursor()
+	defer showCursor()
+
+	fmt.Fprint(os.Stderr, "⏳ Loading punctuation model...\r")
+	modelPath, err := models.Resolve(models.Punctuation, modelPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		return nil, fmt.Errorf("failed to get punctuation model: %w", err)
+	}
+
+	punctuator, err := asr.NewPunctuator(modelPath, provider)
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		return nil, fmt.Errorf("failed to load punctuation model: %w", err)
+	}
+
+	fmt.Fprintln(os.Stderr, "✅ Punctuation model loaded!   ")
+	return punctuator, nil
+}
+
+// transcribeAudio runs the pipeline with a progress bar, skipping chunks
+// that fail to transcribe after reporting them
+func transcribeAudio(audioFile string, boundaries []float64, opts pipeline.Options) ([]types.ChunkResult, []types.Span, time.Duration, error) {
+	startTime := time.Now()
 
+	hideCursor()
+	defer showCursor()
+
+	opts.OnAnalyze = func() {
+		fmt.Fprint(os.Stderr, "⏳ Analyzing audio...\r")
+	}
+	opts.OnChunk = func(i, total int) {
 		elapsed := time.Since(startTime)
 		percent := float64(i+1) / float64(total)
 		eta := time.Duration(float64(elapsed)/percent - float64(elapsed))
 		fmt.Fprint(os.Stderr, renderProgressLine(i+1, total, eta))
-
-		result, err := transcribeChunk(recognizer, audioFile, chunkStart, chunkEnd-chunkStart)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, renderProgressErrorLine(i+1, total, time.Since(startTime), err))
-			continue
+	}
+	opts.OnError = func(i, total int, err error) error {
+		fmt.Fprintln(os.Stderr, renderProgressErrorLine(i+1, total, time.Since(startTime), err))
+		return nil
+	}
+	if onChunk := opts.OnResult; onChunk != nil {
+		opts.OnResult = func(r types.ChunkResult) error {
+			if err := onChunk(r); err != nil {
+				return fmt.Errorf("error writing output: %w", err)
+			}
+			return nil
 		}
+	}
 
-		results = append(results, types.ChunkResult{
-			StartTime:  chunkStart,
-			EndTime:    chunkEnd,
-			Text:       result.Text,
-			Timestamps: result.Timestamps,
-			Tokens:     result.Tokens,
-		})
+	result, err := pipeline.Transcribe(audioFile, boundaries, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		return result.Chunks, result.Speech, time.Since(startTime), err
 	}
 
+	total := len(boundaries) - 1
 	fmt.Fprintln(os.Stderr, renderProgressLine(total, total, 0))
-	return results, time.Since(startTime)
+	return result.Chunks, result.Speech, time.Since(startTime), nil
 }
 
 func openOutput(path string) (io.Writer, func(), error) {
@@ -184,21 +373,6 @@ func openOutput(path string) (io.Writer, func(), error) {
 	return file, func() { file.Close() }, nil
 }
 
-func transcribeChunk(recognizer *asr.Recognizer, audioFile string, start, duration float64) (*asr.Result, error) {
-	tmpDir, err := os.MkdirTemp("", "chough-*")
-	if err != nil {
-		return nil, err
-	}
-	defer os.RemoveAll(tmpDir)
-
-	chunkFile := filepath.Join(tmpDir, "chunk.wav")
-	if err := audio.ExtractChunkWAV(audioFile, chunkFile, start, duration); err != nil {
-		return nil, err
-	}
-
-	return recognizer.Transcribe(chunkFile)
-}
-
 // copyStdinToTemp reads all data from stdin and writes it to a temporary file.
 // Returns the path to the temp file which the caller must clean up.
 func copyStdinToTemp() (string, error) {
@@ -8,6 +8,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/hyperpuncher/chough/internal/asr"
+	"github.com/hyperpuncher/chough/internal/models"
 	"github.com/hyperpuncher/chough/internal/server"
 	"github.com/hyperpuncher/chough/internal/worker"
 )
@@ -18,7 +20,7 @@ func runServer(opts *cliOptions) error {
 	defer showCursor()
 
 	fmt.Fprint(os.Stderr, "⏳ Loading model...\r")
-	recognizer, err := server.LoadRecognizer()
+	recognizer, err := server.LoadRecognizer(opts.asrConfig())
 	if err != nil {
 		fmt.Fprintln(os.Stderr)
 		return err
@@ -26,6 +28,47 @@ func runServer(opts *cliOptions) error {
 	defer recognizer.Close()
 	fmt.Fprintln(os.Stderr, "✅ Model loaded!   ")
 
+	var punctuator *asr.Punctuator
+	if opts.Punctuate {
+		fmt.Fprint(os.Stderr, "⏳ Loading punctuation model...\r")
+		punctuator, err = server.LoadPunctuator(opts.PunctuationModel, opts.Provider)
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+			return err
+		}
+		defer punctuator.Close()
+		fmt.Fprintln(os.Stderr, "✅ Punctuation model loaded!   ")
+	}
+
+	var identifier *asr.LanguageIdentifier
+	if opts.DetectLanguage {
+		fmt.Fprint(os.Stderr, "⏳ Loading language identification model...\r")
+		identifier, err = server.LoadLanguageIdentifier(opts.LanguageIDModel, opts.Provider)
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+			return err
+		}
+		defer identifier.Close()
+		fmt.Fprintln(os.Stderr, "✅ Language identification model loaded!   ")
+	}
+
+	var vad *asr.VoiceActivityDetector
+	if opts.VAD {
+		fmt.Fprint(os.Stderr, "⏳ Loading VAD model...\r")
+		vad, err = server.LoadVAD(opts.VADModel, opts.Provider)
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+			return err
+		}
+		defer vad.Close()
+		fmt.Fprintln(os.Stderr, "✅ VAD model loaded!   ")
+	}
+
+	var languages []string
+	if spec, ok := models.Lookup(recognizer.Config.ModelPath); ok {
+		languages = spec.Languages
+	}
+
 	// Create worker pool
 	serverOpts := &server.ServerOptions{
 		Host:         opts.ServerHost,
@@ -33,8 +76,23 @@ func runServer(opts *cliOptions) error {
 		MaxUploadMB:  int64(opts.MaxUploadMB),
 		Workers:      opts.Workers,
 		MaxQueueSize: 10,
+
+		ModelKind:        recognizer.Config.Kind,
+		Decoding:         recognizer.Config.Decoding(),
+		AllowTranslation: recognizer.Config.SupportsTranslation(),
+		AllowPunctuation: punctuator != nil,
+		AllowLanguageID:  identifier != nil,
+		AllowVAD:         vad != nil,
+		Languages:        languages,
+		Cues:             opts.Cues,
+		URLPolicy:        opts.URLPolicy,
 	}
-	pool := worker.NewPool(opts.Workers, 10, recognizer)
+	pool := worker.NewPool(opts.Workers, 10, worker.Models{
+		Recognizer: recognizer,
+		Punctuator: punctuator,
+		LanguageID: identifier,
+		VAD:        vad,
+	})
 	defer pool.Shutdown()
 
 	// Create HTTP server
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hyperpuncher/chough/internal/audio"
+	"github.com/hyperpuncher/chough/internal/output"
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// validateEmbedSubs checks the --embed-subs, --burn-in and --sub-language
+// flags
+func (o *cliOptions) validateEmbedSubs() error {
+	if o.EmbedSubs == "" {
+		if o.BurnIn {
+			return fmt.Errorf("%w: --burn-in requires --embed-subs", errInvalidArgs)
+		}
+		return nil
+	}
+	if o.AudioFile == "-" {
+		return fmt.Errorf("%w: --embed-subs needs an input file, not stdin", errInvalidArgs)
+	}
+	if ext := strings.ToLower(filepath.Ext(o.EmbedSubs)); !slices.Contains(audio.SubtitleContainers(), ext) {
+		return fmt.Errorf("%w: --embed-subs must end in one of %s", errInvalidArgs, strings.Join(audio.SubtitleContainers(), ", "))
+	}
+	if samePath(o.EmbedSubs, o.AudioFile) || samePath(o.EmbedSubs, o.OutputFile) {
+		return fmt.Errorf("%w: --embed-subs must not overwrite the input or output file", errInvalidArgs)
+	}
+	if o.SubLanguage != "" && (len(o.SubLanguage) != 3 || strings.ToLower(o.SubLanguage) != o.SubLanguage) {
+		return fmt.Errorf("%w: --sub-language must be a three-letter ISO 639-2 code such as eng", errInvalidArgs)
+	}
+	return nil
+}
+
+func samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// embedSubtitles writes the transcript as subtitles to a temp file and
+// muxes (or with --burn-in, renders) them into a copy of the input
+func embedSubtitles(opts *cliOptions, audioFile string, results []types.ChunkResult, meta *types.Metadata) error {
+	format, ext := "vtt", ".vtt"
+	if opts.BurnIn {
+		format, ext = "ass", ".ass"
+	}
+
+	subs, err := os.CreateTemp("", "chough-subs-*"+ext)
+	if err != nil {
+		return fmt.Errorf("failed to create subtitle file: %w", err)
+	}
+	defer os.Remove(subs.Name())
+
+	writeErr := output.Write(subs, format, results, 0, opts.outputOptions(meta))
+	if err := subs.Close(); writeErr == nil {
+		writeErr = err
+	}
+	if writeErr != nil {
+		return fmt.Errorf("failed to write subtitles: %w", writeErr)
+	}
+
+	// Without --sub-language the track is tagged with the transcript's
+	// language, given or identified
+	language := opts.SubLanguage
+	if language == "" {
+		language = audio.TrackLanguage(cmp.Or(output.Language(results), opts.Language))
+	}
+
+	fmt.Fprintf(os.Stderr, "🎬 Writing %s...\n", opts.EmbedSubs)
+	if opts.BurnIn {
+		err = audio.BurnSubtitles(audioFile, subs.Name(), opts.EmbedSubs, language)
+	} else {
+		err = audio.EmbedSubtitles(audioFile, subs.Name(), opts.EmbedSubs, language)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to embed subtitles: %w", err)
+	}
+	return nil
+}
@@ -3,13 +3,18 @@ module github.com/hyperpuncher/chough
 go 1.26
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/k2-fsa/sherpa-onnx-go v1.12.27
+	github.com/mewkiz/flac v1.0.14
 	golang.org/x/term v0.40.0
 )
 
 require (
+	github.com/icza/bitio v1.1.0 // indirect
 	github.com/k2-fsa/sherpa-onnx-go-linux v1.12.28 // indirect
 	github.com/k2-fsa/sherpa-onnx-go-macos v1.12.28 // indirect
 	github.com/k2-fsa/sherpa-onnx-go-windows v1.12.28 // indirect
+	github.com/mewkiz/pkg v0.0.0-20250417130911-3f050ff8c56d // indirect
+	github.com/mewpkg/term v0.0.0-20241026122259-37a80af23985 // indirect
 	golang.org/x/sys v0.41.0 // indirect
 )
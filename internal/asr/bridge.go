@@ -0,0 +1,191 @@
+package asr
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+// Functions and fields not wrapped by sherpa-onnx-go; the library itself is
+// linked by that package. Recognizer and stream types are opaque, so void
+// pointers suffice. Streams are created, decoded and freed through these
+// functions directly rather than through sherpa.OfflineStream.
+
+// Leading fields of SherpaOnnxOfflineRecognizerResult, up to and including
+// ys_log_probs, copied from the c-api.h that sherpa-onnx-go-linux v1.12.28
+// ships (sherpa-onnx v1.12.28). Check them against c-api.h when upgrading.
+typedef struct {
+	const char *text;
+	float *timestamps;
+	int32_t count;
+	const char *tokens;
+	const char *const *tokens_arr;
+	const char *json;
+	const char *lang;
+	const char *emotion;
+	const char *event;
+	float *durations;
+	float *ys_log_probs;
+} choughOfflineResult;
+
+const void *SherpaOnnxCreateOfflineStream(const void *recognizer);
+const void *SherpaOnnxCreateOfflineStreamWithHotwords(const void *recognizer, const char *hotwords);
+void SherpaOnnxDestroyOfflineStream(const void *stream);
+void SherpaOnnxAcceptWaveformOffline(const void *stream, int32_t sample_rate, const float *samples, int32_t n);
+void SherpaOnnxDecodeOfflineStream(const void *recognizer, const void *stream);
+const choughOfflineResult *SherpaOnnxGetOfflineStreamResult(const void *stream);
+void SherpaOnnxDestroyOfflineRecognizerResult(const choughOfflineResult *r);
+
+const char *SherpaOfflinePunctuationAddPunct(const void *punct, const char *text);
+void SherpaOfflinePunctuationFreeText(const char *text);
+
+typedef struct {
+	const char *lang;
+} choughLanguageResult;
+
+const void *SherpaOnnxSpokenLanguageIdentificationCreateOfflineStream(const void *slid);
+const choughLanguageResult *SherpaOnnxSpokenLanguageIdentificationCompute(const void *slid, const void *stream);
+void SherpaOnnxDestroySpokenLanguageIdentificationResult(const choughLanguageResult *r);
+*/
+import "C"
+
+import (
+	"strings"
+	"unsafe"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// offlineStream is an offline stream of the C API
+type offlineStream struct {
+	impl unsafe.Pointer
+}
+
+// newOfflineStream creates a stream for the recognizer, biased towards the
+// given hotwords if there are any. The caller must free it with delete.
+func newOfflineStream(recognizer *sherpa.OfflineRecognizer, hotwords []string) *offlineStream {
+	if len(hotwords) == 0 {
+		return &offlineStream{C.SherpaOnnxCreateOfflineStream(handle(recognizer))}
+	}
+
+	cHotwords := C.CString(joinHotwords(hotwords))
+	defer C.free(unsafe.Pointer(cHotwords))
+	return &offlineStream{C.SherpaOnnxCreateOfflineStreamWithHotwords(handle(recognizer), cHotwords)}
+}
+
+// newLanguageStream creates a stream for language identification. The
+// caller must free it with delete.
+func newLanguageStream(slid *sherpa.SpokenLanguageIdentification) *offlineStream {
+	return &offlineStream{C.SherpaOnnxSpokenLanguageIdentificationCreateOfflineStream(handle(slid))}
+}
+
+func (s *offlineStream) delete() {
+	C.SherpaOnnxDestroyOfflineStream(s.impl)
+}
+
+// acceptWaveform computes the features of the samples. Call it once per
+// stream.
+func (s *offlineStream) acceptWaveform(sampleRate int, samples []float32) {
+	var p *C.float
+	if len(samples) > 0 {
+		p = (*C.float)(unsafe.Pointer(&samples[0]))
+	}
+	C.SherpaOnnxAcceptWaveformOffline(s.impl, C.int32_t(sampleRate), p, C.int32_t(len(samples)))
+}
+
+func (s *offlineStream) decode(recognizer *sherpa.OfflineRecognizer) {
+	C.SherpaOnnxDecodeOfflineStream(handle(recognizer), s.impl)
+}
+
+// result returns the decoded text and tokens. Timestamps, durations and log
+// probabilities are nil when the model does not provide them.
+func (s *offlineStream) result() *Result {
+	r := C.SherpaOnnxGetOfflineStreamResult(s.impl)
+	if r == nil {
+		return &Result{}
+	}
+	defer C.SherpaOnnxDestroyOfflineRecognizerResult(r)
+
+	n := int(r.count)
+	if n == 0 {
+		return &Result{}
+	}
+
+	result := &Result{
+		Text:       C.GoString(r.text),
+		Tokens:     make([]string, n),
+		Timestamps: floats(r.timestamps, n),
+		Durations:  floats(r.durations, n),
+		LogProbs:   floats(r.ys_log_probs, n),
+		Language:   NormalizeLanguage(C.GoString(r.lang)),
+	}
+	for i, t := range unsafe.Slice(r.tokens_arr, n) {
+		result.Tokens[i] = C.GoString(t)
+	}
+	return result
+}
+
+// floats copies n floats from C, or returns nil if p is nil
+func floats(p *C.float, n int) []float32 {
+	if p == nil {
+		return nil
+	}
+	values := make([]float32, n)
+	for i, v := range unsafe.Slice(p, n) {
+		values[i] = float32(v)
+	}
+	return values
+}
+
+// joinHotwords formats hotwords the way sherpa-onnx expects them per stream:
+// one phrase per entry, separated by "/"
+func joinHotwords(hotwords []string) string {
+	phrases := make([]string, 0, len(hotwords))
+	for _, h := range hotwords {
+		h = strings.Join(strings.Fields(strings.ReplaceAll(h, "/", " ")), " ")
+		if h != "" {
+			phrases = append(phrases, h)
+		}
+	}
+	return strings.Join(phrases, "/")
+}
+
+// addPunct is OfflinePunctuation.AddPunct without leaking the input string
+func addPunct(punct *sherpa.OfflinePunctuation, text string) string {
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+
+	p := C.SherpaOfflinePunctuationAddPunct(handle(punct), cText)
+	defer C.SherpaOfflinePunctuationFreeText(p)
+	return C.GoString(p)
+}
+
+// computeLanguage is SpokenLanguageIdentification.Compute without leaking
+// the result
+func computeLanguage(slid *sherpa.SpokenLanguageIdentification, stream *offlineStream) string {
+	r := C.SherpaOnnxSpokenLanguageIdentificationCompute(handle(slid), stream.impl)
+	if r == nil {
+		return ""
+	}
+	defer C.SherpaOnnxDestroySpokenLanguageIdentificationResult(r)
+	return C.GoString(r.lang)
+}
+
+// wrapper lists the sherpa-onnx-go types whose C pointer is read here.
+// Each is a struct with a single unexported impl pointer (sherpa-onnx-go
+// v1.12.28). They are only read, never written.
+type wrapper interface {
+	sherpa.OfflineRecognizer | sherpa.OfflinePunctuation | sherpa.SpokenLanguageIdentification
+}
+
+// Fail the build if a wrapper is no longer exactly one pointer, which then
+// sits at offset 0: the index is out of range if it grew and the constant
+// overflows if it shrank
+var (
+	_ = [1]struct{}{}[unsafe.Sizeof(sherpa.OfflineRecognizer{})-unsafe.Sizeof(unsafe.Pointer(nil))]
+	_ = [1]struct{}{}[unsafe.Sizeof(sherpa.OfflinePunctuation{})-unsafe.Sizeof(unsafe.Pointer(nil))]
+	_ = [1]struct{}{}[unsafe.Sizeof(sherpa.SpokenLanguageIdentification{})-unsafe.Sizeof(unsafe.Pointer(nil))]
+)
+
+// handle returns the C pointer held by a sherpa-onnx-go wrapper
+func handle[T wrapper](v *T) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(v))
+}
@@ -1,20 +1,237 @@
 package asr
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperpuncher/chough/internal/models"
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// Decoding methods supported by sherpa-onnx offline transducers
+const (
+	DecodingGreedy     = "greedy_search"
+	DecodingBeamSearch = "modified_beam_search"
+)
+
+// Tasks supported by Whisper models
+const (
+	TaskTranscribe = "transcribe"
+	TaskTranslate  = "translate" // to English
+)
+
+// whisperWindow is the longest audio Whisper decodes at once
+const whisperWindow = 30 // seconds
+
 // Config holds ASR configuration.
 type Config struct {
 	ModelPath  string
+	Kind       string // models.KindTransducer or models.KindWhisper
+	Task       string // Whisper only
 	NumThreads int
 	SampleRate int
 	FeatureDim int
 	Provider   string
+
+	// Decoding
+	DecodingMethod string
+	MaxActivePaths int // modified_beam_search only
+	BlankPenalty   float32
+
+	// Hotwords (modified_beam_search only)
+	HotwordsFile  string
+	HotwordsScore float32
+}
+
+// Decoding holds the decoding settings a stream may override
+type Decoding struct {
+	Method         string
+	MaxActivePaths int // modified_beam_search only
+	BlankPenalty   float32
+}
+
+// StreamOptions holds per-stream decoding options
+type StreamOptions struct {
+	// Decoding overrides the Config's decoding settings if set
+	Decoding *Decoding
+
+	// Hotwords are boosted for this stream in addition to HotwordsFile
+	Hotwords []string
+
+	// Language is the known spoken language, reported instead of detecting
+	// it and passed to Whisper. LanguageID, if set, identifies it otherwise.
+	Language   string
+	LanguageID *LanguageIdentifier
+
+	// Task overrides Config.Task for Whisper models
+	Task string
+
+	// VAD, if set, limits decoding to the speech it detects
+	VAD *VoiceActivityDetector
 }
 
 func DefaultConfig(modelPath string) *Config {
 	return &Config{
-		ModelPath:  modelPath,
-		NumThreads: 4,
-		SampleRate: 16000,
-		FeatureDim: 80,
-		Provider:   "cpu",
+		ModelPath:      modelPath,
+		Kind:           models.KindTransducer,
+		Task:           TaskTranscribe,
+		NumThreads:     4,
+		SampleRate:     16000,
+		FeatureDim:     80,
+		Provider:       "cpu",
+		DecodingMethod: DecodingGreedy,
+		MaxActivePaths: 4,
+		HotwordsScore:  1.5,
+	}
+}
+
+// ParseDecodingMethod normalizes a user-supplied decoding method.
+// "greedy" and "beam" are accepted as shorthands.
+func ParseDecodingMethod(method string) (string, error) {
+	switch method {
+	case "greedy", DecodingGreedy:
+		return DecodingGreedy, nil
+	case "beam", DecodingBeamSearch:
+		return DecodingBeamSearch, nil
+	default:
+		return "", fmt.Errorf("unknown decoding method %q (valid: greedy, modified_beam_search)", method)
+	}
+}
+
+// ParseTask checks a user-supplied task
+func ParseTask(task string) (string, error) {
+	switch task {
+	case TaskTranscribe, TaskTranslate:
+		return task, nil
+	default:
+		return "", fmt.Errorf("unknown task %q (valid: transcribe, translate)", task)
+	}
+}
+
+// Validate checks the config for values sherpa-onnx would reject
+func (c *Config) Validate() error {
+	if _, err := ParseDecodingMethod(c.DecodingMethod); err != nil {
+		return err
+	}
+	if _, err := ParseTask(c.Task); err != nil {
+		return err
+	}
+	if err := c.Decoding().Validate(c.Kind); err != nil {
+		return err
+	}
+	if c.Kind == models.KindWhisper {
+		if c.Task == TaskTranslate && !c.SupportsTranslation() {
+			return fmt.Errorf("model %s cannot translate", filepath.Base(c.ModelPath))
+		}
+	} else if c.Task == TaskTranslate {
+		return fmt.Errorf("translation requires a Whisper model")
+	}
+	if c.NumThreads < 1 {
+		return fmt.Errorf("threads must be at least 1, got %d", c.NumThreads)
+	}
+	if c.HotwordsFile != "" {
+		if !c.SupportsHotwords() {
+			return fmt.Errorf("hotwords require --decoding-method %s", DecodingBeamSearch)
+		}
+		if _, err := os.Stat(c.HotwordsFile); err != nil {
+			return fmt.Errorf("hotwords file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Decoding returns the configured decoding settings
+func (c *Config) Decoding() Decoding {
+	return Decoding{
+		Method:         c.DecodingMethod,
+		MaxActivePaths: c.MaxActivePaths,
+		BlankPenalty:   c.BlankPenalty,
+	}
+}
+
+// streamDecoding returns the decoding settings of a stream
+func (c *Config) streamDecoding(opts StreamOptions) Decoding {
+	if opts.Decoding != nil {
+		return *opts.Decoding
+	}
+	return c.Decoding()
+}
+
+// Validate checks decoding settings for a model of the given kind
+func (d Decoding) Validate(kind string) error {
+	if _, err := ParseDecodingMethod(d.Method); err != nil {
+		return err
+	}
+	if kind == models.KindWhisper && d.Method != DecodingGreedy {
+		return fmt.Errorf("Whisper models only support %s", DecodingGreedy)
+	}
+	if d.MaxActivePaths < 1 {
+		return fmt.Errorf("max active paths must be at least 1, got %d", d.MaxActivePaths)
+	}
+	if d.BlankPenalty < 0 {
+		return fmt.Errorf("blank penalty must not be negative, got %g", d.BlankPenalty)
+	}
+	return nil
+}
+
+// SupportsHotwords reports whether the decoding method can apply hotwords
+func (d Decoding) SupportsHotwords() bool {
+	return d.Method == DecodingBeamSearch
+}
+
+// SupportsHotwords reports whether the decoding method can apply hotwords
+func (c *Config) SupportsHotwords() bool {
+	return c.Decoding().SupportsHotwords() && c.Kind != models.KindWhisper
+}
+
+// SupportsTranslation reports whether the model can translate to English
+func (c *Config) SupportsTranslation() bool {
+	if c.Kind != models.KindWhisper {
+		return false
+	}
+	spec, ok := models.Lookup(c.ModelPath)
+	return !ok || spec.Translate
+}
+
+// MaxChunkSize returns the longest chunk in seconds the model decodes
+// whole, or 0 if there is no limit
+func (c *Config) MaxChunkSize() int {
+	if c.Kind == models.KindWhisper {
+		return whisperWindow
+	}
+	return 0
+}
+
+// Settings returns the settings echoed in output metadata
+func (c *Config) Settings() types.ASRSettings {
+	return c.StreamSettings(StreamOptions{})
+}
+
+// StreamSettings returns the settings echoed in output metadata for
+// streams decoded with opts
+func (c *Config) StreamSettings(opts StreamOptions) types.ASRSettings {
+	d := c.streamDecoding(opts)
+	s := types.ASRSettings{
+		Model:          filepath.Base(c.ModelPath),
+		Provider:       c.Provider,
+		NumThreads:     c.NumThreads,
+		DecodingMethod: d.Method,
+		BlankPenalty:   d.BlankPenalty,
+		Hotwords:       len(opts.Hotwords),
+	}
+	if d.Method == DecodingBeamSearch {
+		s.MaxActivePaths = d.MaxActivePaths
+		if c.HotwordsFile != "" {
+			s.HotwordsFile = filepath.Base(c.HotwordsFile)
+			s.HotwordsScore = c.HotwordsScore
+		}
+	}
+	if c.Kind == models.KindWhisper {
+		s.Task = c.Task
+		if opts.Task != "" {
+			s.Task = opts.Task
+		}
 	}
+	return s
 }
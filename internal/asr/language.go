@@ -0,0 +1,80 @@
+package asr
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hyperpuncher/chough/internal/models"
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// languageIDWindow is the audio Whisper looks at to identify the language
+const languageIDWindow = 30 // seconds
+
+// LanguageIdentifier identifies the spoken language with a multilingual
+// Whisper model
+type LanguageIdentifier struct {
+	Name string
+	slid *sherpa.SpokenLanguageIdentification
+	mu   sync.Mutex // the model is shared by server workers
+}
+
+// NewLanguageIdentifier loads the language identification model from
+// modelPath
+func NewLanguageIdentifier(modelPath, provider string) (*LanguageIdentifier, error) {
+	encoder, decoder, _, ok := models.WhisperFiles(modelPath)
+	if !ok {
+		return nil, fmt.Errorf("no Whisper model in %s", modelPath)
+	}
+	config := sherpa.SpokenLanguageIdentificationConfig{
+		Whisper: sherpa.SpokenLanguageIdentificationWhisperConfig{
+			Encoder: encoder,
+			Decoder: decoder,
+		},
+		NumThreads: 1,
+		Provider:   provider,
+	}
+
+	slid := sherpa.NewSpokenLanguageIdentification(&config)
+	if slid == nil || handle(slid) == nil {
+		return nil, fmt.Errorf("failed to create language identification model")
+	}
+
+	return &LanguageIdentifier{
+		Name: filepath.Base(modelPath),
+		slid: slid,
+	}, nil
+}
+
+// Close cleans up the language identification model
+func (l *LanguageIdentifier) Close() {
+	if l.slid != nil {
+		sherpa.DeleteSpokenLanguageIdentification(l.slid)
+		l.slid = nil
+	}
+}
+
+// Identify returns the ISO 639-1 code of the language spoken at the start
+// of the samples
+func (l *LanguageIdentifier) Identify(sampleRate int, samples []float32) string {
+	if n := sampleRate * languageIDWindow; len(samples) > n {
+		samples = samples[:n]
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stream := newLanguageStream(l.slid)
+	defer stream.delete()
+	stream.acceptWaveform(sampleRate, samples)
+	return NormalizeLanguage(computeLanguage(l.slid, stream))
+}
+
+// NormalizeLanguage turns a model's language tag ("<|de|>", "DE") into a
+// lower-case code
+func NormalizeLanguage(lang string) string {
+	lang = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(lang), "<|"), "|>")
+	return strings.ToLower(lang)
+}
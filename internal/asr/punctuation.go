@@ -0,0 +1,131 @@
+package asr
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hyperpuncher/chough/internal/models"
+	"github.com/hyperpuncher/chough/internal/output"
+	"github.com/hyperpuncher/chough/internal/types"
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// Punctuator restores punctuation and sentence casing for models that
+// output neither
+type Punctuator struct {
+	Name  string
+	punct *sherpa.OfflinePunctuation
+	mu    sync.Mutex // the model is shared by server workers
+}
+
+// NewPunctuator loads the punctuation model from modelPath
+func NewPunctuator(modelPath, provider string) (*Punctuator, error) {
+	config := sherpa.OfflinePunctuationConfig{
+		Model: sherpa.OfflinePunctuationModelConfig{
+			CtTransformer: filepath.Join(modelPath, models.PunctuationModelFile),
+			NumThreads:    1, // small model, one thread is plenty
+			Provider:      provider,
+		},
+	}
+
+	punct := sherpa.NewOfflinePunctuation(&config)
+	if punct == nil {
+		return nil, fmt.Errorf("failed to create punctuation model")
+	}
+
+	return &Punctuator{
+		Name:  filepath.Base(modelPath),
+		punct: punct,
+	}, nil
+}
+
+// Close cleans up the punctuation model
+func (p *Punctuator) Close() {
+	if p.punct != nil {
+		sherpa.DeleteOfflinePunc(p.punct)
+		p.punct = nil
+	}
+}
+
+// Apply punctuates the chunk, attaching marks to the last token of each word
+// so that timestamps stay aligned, and capitalizes sentence starts.
+// sentenceStart tells whether the chunk begins a new sentence; the return
+// value tells the same for the chunk that follows.
+func (p *Punctuator) Apply(r *types.ChunkResult, sentenceStart bool) bool {
+	words := types.GroupWords(*r)
+	if len(words) == 0 {
+		return sentenceStart
+	}
+
+	plain := make([]string, len(words))
+	for i, w := range words {
+		plain[i] = w.Text
+	}
+	p.mu.Lock()
+	punctuated := strings.Fields(addPunct(p.punct, strings.Join(plain, " ")))
+	p.mu.Unlock()
+
+	// The model only inserts marks, so words pair up one-to-one. Anything
+	// else (e.g. CJK re-spacing) is left untouched rather than misaligned.
+	if len(punctuated) != len(words) {
+		return sentenceStart
+	}
+
+	tokens := make([]string, len(r.Tokens))
+	copy(tokens, r.Tokens)
+
+	for i, w := range words {
+		if !sameWord(w.Text, punctuated[i]) {
+			continue
+		}
+		if marks := addedMarks(w.Text, punctuated[i]); marks != "" {
+			tokens[w.LastToken] += marks
+		}
+		if sentenceStart || isPronounI(w.Text) {
+			tokens[w.FirstToken] = capitalize(tokens[w.FirstToken])
+		}
+		sentenceStart = output.IsSentenceEnd(punctuated[i])
+	}
+
+	r.Tokens = tokens
+	r.Text = strings.TrimSpace(strings.Join(tokens, ""))
+	r.UpdateWords()
+	return sentenceStart
+}
+
+func sameWord(a, b string) bool {
+	return strings.EqualFold(stripPunct(a), stripPunct(b))
+}
+
+func stripPunct(s string) string {
+	return strings.TrimRightFunc(s, unicode.IsPunct)
+}
+
+// addedMarks returns the trailing punctuation of punctuated that word lacks
+func addedMarks(word, punctuated string) string {
+	have := word[len(stripPunct(word)):]
+	want := punctuated[len(stripPunct(punctuated)):]
+	if have != "" || want == "" {
+		return ""
+	}
+	return want
+}
+
+func isPronounI(word string) bool {
+	w := stripPunct(word)
+	return w == "i" || strings.HasPrefix(w, "i'")
+}
+
+// capitalize upper-cases the first letter of a token, keeping its leading space
+func capitalize(tok string) string {
+	trimmed := strings.TrimLeft(tok, " ")
+	r, size := utf8.DecodeRuneInString(trimmed)
+	if r == utf8.RuneError || unicode.IsUpper(r) {
+		return tok
+	}
+	return tok[:len(tok)-len(trimmed)] + string(unicode.ToUpper(r)) + trimmed[size:]
+}
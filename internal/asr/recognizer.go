@@ -2,10 +2,15 @@ package asr
 
 import (
 	"fmt"
+	"math"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/hyperpuncher/chough/internal/audio"
 	"github.com/hyperpuncher/chough/internal/models"
+	"github.com/hyperpuncher/chough/internal/types"
 	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
 )
 
@@ -13,26 +18,73 @@ import (
 type Recognizer struct {
 	Config     *Config
 	recognizer *sherpa.OfflineRecognizer
+
+	// Decoding settings, and Whisper's language and task, come from the
+	// recognizer config, so streams that need others switch it and decode
+	// while holding mu exclusively
+	sherpaConfig sherpa.OfflineRecognizerConfig
+	current      decodeKey
+	mu           sync.RWMutex
+}
+
+// decodeKey is the part of the recognizer config a stream may need changed
+type decodeKey struct {
+	decoding Decoding
+	language string // Whisper only, "" detects it
+	task     string // Whisper only
 }
 
 // NewRecognizer creates a new ASR recognizer
 func NewRecognizer(cfg *Config) (*Recognizer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	sherpaConfig := sherpa.OfflineRecognizerConfig{
 		FeatConfig: sherpa.FeatureConfig{
 			SampleRate: cfg.SampleRate,
 			FeatureDim: cfg.FeatureDim,
 		},
 		ModelConfig: sherpa.OfflineModelConfig{
-			Transducer: sherpa.OfflineTransducerModelConfig{
-				Encoder: filepath.Join(cfg.ModelPath, models.EncoderFile),
-				Decoder: filepath.Join(cfg.ModelPath, models.DecoderFile),
-				Joiner:  filepath.Join(cfg.ModelPath, models.JoinerFile),
-			},
-			Tokens:     filepath.Join(cfg.ModelPath, models.TokensFile),
 			NumThreads: cfg.NumThreads,
 			Provider:   cfg.Provider,
-			ModelType:  "nemo_transducer",
 		},
+		DecodingMethod: cfg.DecodingMethod,
+		MaxActivePaths: cfg.MaxActivePaths,
+		BlankPenalty:   cfg.BlankPenalty,
+		HotwordsFile:   cfg.HotwordsFile,
+		HotwordsScore:  cfg.HotwordsScore,
+	}
+
+	switch cfg.Kind {
+	case models.KindWhisper:
+		encoder, decoder, tokens, ok := models.WhisperFiles(cfg.ModelPath)
+		if !ok {
+			return nil, fmt.Errorf("no Whisper model in %s", cfg.ModelPath)
+		}
+		sherpaConfig.ModelConfig.Whisper = sherpa.OfflineWhisperModelConfig{
+			Encoder:               encoder,
+			Decoder:               decoder,
+			Task:                  cfg.Task,
+			TailPaddings:          -1,
+			EnableTokenTimestamps: 1,
+		}
+		sherpaConfig.ModelConfig.Tokens = tokens
+		sherpaConfig.ModelConfig.ModelType = models.KindWhisper
+	default:
+		sherpaConfig.ModelConfig.Transducer = sherpa.OfflineTransducerModelConfig{
+			Encoder: filepath.Join(cfg.ModelPath, models.EncoderFile),
+			Decoder: filepath.Join(cfg.ModelPath, models.DecoderFile),
+			Joiner:  filepath.Join(cfg.ModelPath, models.JoinerFile),
+		}
+		sherpaConfig.ModelConfig.Tokens = filepath.Join(cfg.ModelPath, models.TokensFile)
+		sherpaConfig.ModelConfig.ModelType = models.KindTransducer
+
+		// Hotwords are encoded with the model's BPE vocabulary when it ships one
+		if bpeVocab := filepath.Join(cfg.ModelPath, models.BpeVocabFile); fileExists(bpeVocab) {
+			sherpaConfig.ModelConfig.ModelingUnit = "bpe"
+			sherpaConfig.ModelConfig.BpeVocab = bpeVocab
+		}
 	}
 
 	recognizer := sherpa.NewOfflineRecognizer(&sherpaConfig)
@@ -41,16 +93,28 @@ func NewRecognizer(cfg *Config) (*Recognizer, error) {
 	}
 
 	return &Recognizer{
-		Config:     cfg,
-		recognizer: recognizer,
+		Config:       cfg,
+		recognizer:   recognizer,
+		sherpaConfig: sherpaConfig,
+		current:      decodeKey{decoding: cfg.Decoding(), task: sherpaConfig.ModelConfig.Whisper.Task},
 	}, nil
 }
 
 // Transcribe transcribes an audio file
-func (r *Recognizer) Transcribe(audioPath string) (*Result, error) {
+func (r *Recognizer) Transcribe(audioPath string, opts StreamOptions) (*Result, error) {
 	if r == nil || r.recognizer == nil {
 		return nil, fmt.Errorf("recognizer not initialized")
 	}
+	decoding := r.Config.streamDecoding(opts)
+	if err := decoding.Validate(r.Config.Kind); err != nil {
+		return nil, err
+	}
+	if len(opts.Hotwords) > 0 && !decoding.SupportsHotwords() {
+		return nil, fmt.Errorf("hotwords require decoding method %s", DecodingBeamSearch)
+	}
+	if opts.Task == TaskTranslate && !r.Config.SupportsTranslation() {
+		return nil, fmt.Errorf("model %s cannot translate", filepath.Base(r.Config.ModelPath))
+	}
 
 	// Read wave file using pure Go implementation (no C memory leaks!)
 	wave, err := audio.ReadWave(audioPath)
@@ -58,25 +122,96 @@ func (r *Recognizer) Transcribe(audioPath string) (*Result, error) {
 		return nil, fmt.Errorf("failed to read wave file: %w", err)
 	}
 
-	// Create stream with EXPLICIT cleanup via defer
-	stream := sherpa.NewOfflineStream(r.recognizer)
-	defer sherpa.DeleteOfflineStream(stream) // ← KEY: prevents memory leak!
+	var result *Result
+	speech := wave.Samples
+	if opts.VAD != nil {
+		result, speech = r.decodeSpeech(wave, opts)
+	} else {
+		result = r.decode(wave.SampleRate, wave.Samples, opts)
+	}
+
+	// Prefer the given language, then identification, then whatever the
+	// model reports itself. Silent chunks have no language.
+	if strings.TrimSpace(result.Text) == "" {
+		result.Language = ""
+	} else if opts.Language != "" {
+		result.Language = opts.Language
+	} else if opts.LanguageID != nil {
+		result.Language = opts.LanguageID.Identify(wave.SampleRate, speech)
+	}
+	return result, nil
+}
+
+// decode transcribes samples in one stream
+func (r *Recognizer) decode(sampleRate int, samples []float32, opts StreamOptions) *Result {
+	// Create stream with EXPLICIT cleanup via defer. The recognizer must not
+	// switch its config meanwhile, which also decides how hotwords are built.
+	r.mu.RLock()
+	stream := newOfflineStream(r.recognizer, opts.Hotwords)
+	r.mu.RUnlock()
+	defer stream.delete() // ← KEY: prevents memory leak!
 
 	// Process audio
-	stream.AcceptWaveform(wave.SampleRate, wave.Samples)
-	r.recognizer.Decode(stream)
+	stream.acceptWaveform(sampleRate, samples)
+	key := decodeKey{decoding: r.Config.streamDecoding(opts)}
+	if r.Config.Kind == models.KindWhisper {
+		key.language = opts.Language
+		key.task = opts.Task
+		if key.task == "" {
+			key.task = r.Config.Task
+		}
+	}
+	r.decodeWith(stream, key)
 
-	// Get result
-	sherpaResult := stream.GetResult()
-	if sherpaResult == nil {
-		return &Result{Text: ""}, nil
+	return stream.result()
+}
+
+// decodeSpeech transcribes only the speech opts.VAD finds in the wave, one
+// stream per span, and returns the joined result along with the speech
+// samples
+func (r *Recognizer) decodeSpeech(wave *audio.Wave, opts StreamOptions) (*Result, []float32) {
+	result := &Result{Speech: opts.VAD.Detect(wave.Samples)}
+	var speech []float32
+	for _, span := range result.Speech {
+		from := int(span.Start * float64(wave.SampleRate))
+		to := min(int(span.End*float64(wave.SampleRate)), len(wave.Samples))
+		if from >= to {
+			continue
+		}
+		samples := wave.Samples[from:to]
+		speech = append(speech, samples...)
+		result.append(r.decode(wave.SampleRate, samples, opts), span.Start)
 	}
+	return result, speech
+}
 
-	return &Result{
-		Text:       sherpaResult.Text,
-		Timestamps: sherpaResult.Timestamps,
-		Tokens:     sherpaResult.Tokens,
-	}, nil
+// decodeWith decodes the stream with the config in key. Streams that match
+// the recognizer config decode concurrently; others switch the config and
+// decode holding the lock exclusively, so no stream decodes with a config
+// another stream set.
+func (r *Recognizer) decodeWith(stream *offlineStream, key decodeKey) {
+	r.mu.RLock()
+	if r.current == key {
+		stream.decode(r.recognizer)
+		r.mu.RUnlock()
+		return
+	}
+	r.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != key {
+		r.sherpaConfig.DecodingMethod = key.decoding.Method
+		r.sherpaConfig.MaxActivePaths = key.decoding.MaxActivePaths
+		r.sherpaConfig.BlankPenalty = key.decoding.BlankPenalty
+		if r.Config.Kind == models.KindWhisper {
+			r.sherpaConfig.ModelConfig.Whisper.Language = key.language
+			r.sherpaConfig.ModelConfig.Whisper.Task = key.task
+		}
+		r.recognizer.SetConfig(&r.sherpaConfig)
+		r.current = key
+	}
+	stream.decode(r.recognizer)
 }
 
 // Close cleans up the recognizer
@@ -87,9 +222,78 @@ func (r *Recognizer) Close() {
 	}
 }
 
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 // Result holds transcription result
 type Result struct {
 	Text       string
 	Timestamps []float32
 	Tokens     []string
+	Durations  []float32 // per token, nil unless the model predicts them (TDT)
+	LogProbs   []float32 // per token, nil if the model has none
+	Language   string    // ISO 639-1, empty if unknown
+
+	// Speech holds the spans decoded when VAD is used, in seconds from the
+	// start of the audio; the rest was skipped
+	Speech []types.Span
+}
+
+// append adds the result of audio starting offset seconds in. Durations
+// and log probabilities are only kept while every part has them.
+func (r *Result) append(part *Result, offset float64) {
+	if text := strings.TrimSpace(part.Text); text != "" {
+		if r.Text != "" {
+			r.Text += " "
+		}
+		r.Text += text
+	}
+	if r.Language == "" {
+		r.Language = part.Language
+	}
+
+	aligned := len(r.Durations) == len(r.Tokens)
+	if aligned && len(part.Durations) == len(part.Tokens) {
+		r.Durations = append(r.Durations, part.Durations...)
+	} else {
+		r.Durations = nil
+	}
+	aligned = len(r.LogProbs) == len(r.Tokens)
+	if aligned && len(part.LogProbs) == len(part.Tokens) {
+		r.LogProbs = append(r.LogProbs, part.LogProbs...)
+	} else {
+		r.LogProbs = nil
+	}
+
+	r.Tokens = append(r.Tokens, part.Tokens...)
+	for _, t := range part.Timestamps {
+		r.Timestamps = append(r.Timestamps, t+float32(offset))
+	}
+}
+
+// Chunk converts the result of a chunk spanning [start, end] seconds
+func (r *Result) Chunk(start, end float64) types.ChunkResult {
+	chunk := types.ChunkResult{
+		StartTime:  start,
+		EndTime:    end,
+		Text:       r.Text,
+		Timestamps: r.Timestamps,
+		Tokens:     r.Tokens,
+		Language:   r.Language,
+	}
+	if len(r.Durations) == len(r.Tokens) {
+		chunk.Durations = r.Durations
+	}
+
+	if len(r.LogProbs) == len(r.Tokens) && len(r.LogProbs) > 0 {
+		chunk.Confidences = make([]float32, len(r.LogProbs))
+		for i, lp := range r.LogProbs {
+			chunk.Confidences[i] = float32(math.Exp(float64(lp)))
+		}
+	}
+
+	chunk.UpdateWords()
+	return chunk
 }
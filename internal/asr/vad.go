@@ -0,0 +1,106 @@
+package asr
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/hyperpuncher/chough/internal/models"
+	"github.com/hyperpuncher/chough/internal/types"
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// Silero VAD settings. Speech segments stay well under Whisper's 30 seconds,
+// and are padded so that word onsets and endings are not clipped.
+const (
+	vadWindowSize = 512 // samples per step at 16 kHz
+	vadThreshold  = 0.5
+	vadMinSilence = 0.5  // seconds of silence that end a segment
+	vadMinSpeech  = 0.25 // seconds, shorter blips are ignored
+	vadMaxSpeech  = 20   // seconds, longer segments are split
+	vadPadding    = 0.2  // seconds added around each segment
+	vadBufferSize = 60   // seconds buffered by the detector
+	vadSampleRate = 16000
+)
+
+// vadMaxSpan is the longest span Detect returns
+const vadMaxSpan = vadMaxSpeech + 2*vadPadding
+
+// VoiceActivityDetector finds speech in audio with the Silero VAD model
+type VoiceActivityDetector struct {
+	Name string
+	vad  *sherpa.VoiceActivityDetector
+	mu   sync.Mutex // the model is shared by server workers
+}
+
+// NewVoiceActivityDetector loads the VAD model from modelPath
+func NewVoiceActivityDetector(modelPath, provider string) (*VoiceActivityDetector, error) {
+	config := sherpa.VadModelConfig{
+		SileroVad: sherpa.SileroVadModelConfig{
+			Model:              filepath.Join(modelPath, models.VADModelFile),
+			Threshold:          vadThreshold,
+			MinSilenceDuration: vadMinSilence,
+			MinSpeechDuration:  vadMinSpeech,
+			WindowSize:         vadWindowSize,
+			MaxSpeechDuration:  vadMaxSpeech,
+		},
+		SampleRate: vadSampleRate,
+		NumThreads: 1, // small model, one thread is plenty
+		Provider:   provider,
+	}
+
+	vad := sherpa.NewVoiceActivityDetector(&config, vadBufferSize)
+	if vad == nil {
+		return nil, fmt.Errorf("failed to create voice activity detector")
+	}
+
+	return &VoiceActivityDetector{
+		Name: filepath.Base(modelPath),
+		vad:  vad,
+	}, nil
+}
+
+// Close cleans up the VAD model
+func (v *VoiceActivityDetector) Close() {
+	if v.vad != nil {
+		sherpa.DeleteVoiceActivityDetector(v.vad)
+		v.vad = nil
+	}
+}
+
+// Detect returns the padded speech spans of 16 kHz samples, in seconds from
+// their start. Spans the padding makes overlap are merged up to vadMaxSpan.
+func (v *VoiceActivityDetector) Detect(samples []float32) []types.Span {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.vad.Reset()
+	var spans []types.Span
+	collect := func() {
+		for !v.vad.IsEmpty() {
+			segment := v.vad.Front()
+			v.vad.Pop()
+
+			start := float64(segment.Start)/vadSampleRate - vadPadding
+			end := float64(segment.Start+len(segment.Samples))/vadSampleRate + vadPadding
+			start = max(start, 0)
+			end = min(end, float64(len(samples))/vadSampleRate)
+			if n := len(spans); n > 0 && start <= spans[n-1].End {
+				if end-spans[n-1].Start <= vadMaxSpan {
+					spans[n-1].End = max(spans[n-1].End, end)
+					continue
+				}
+				start = spans[n-1].End
+			}
+			spans = append(spans, types.Span{Start: start, End: end})
+		}
+	}
+
+	for i := 0; i < len(samples); i += vadWindowSize {
+		v.vad.AcceptWaveform(samples[i:min(i+vadWindowSize, len(samples))])
+		collect()
+	}
+	v.vad.Flush()
+	collect()
+	return spans
+}
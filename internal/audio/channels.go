@@ -0,0 +1,69 @@
+package audio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseChannel parses a user-supplied channel: "left", "right" or a channel
+// number from 1. It returns the 0-based channel, or DownmixChannels for "".
+func ParseChannel(s string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return DownmixChannels, nil
+	case "left":
+		return 0, nil
+	case "right":
+		return 1, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid channel %q (valid: left, right or a number from 1)", s)
+	}
+	return n - 1, nil
+}
+
+// ChannelLabel names a 0-based channel of a file with the given number of
+// channels: Left and Right for stereo, else "Channel N"
+func ChannelLabel(channel, channels int) string {
+	if channels == 2 {
+		return [...]string{"Left", "Right"}[channel]
+	}
+	return fmt.Sprintf("Channel %d", channel+1)
+}
+
+// CheckChannel returns an error if channel is not DownmixChannels and not
+// one of the stream's channels
+func (s Stream) CheckChannel(channel int) error {
+	if channel != DownmixChannels && channel >= s.Channels {
+		return fmt.Errorf("channel %d out of range (audio stream %d has %d)", channel+1, s.Index, s.Channels)
+	}
+	return nil
+}
+
+// Track is audio transcribed on its own: one channel, or the downmix
+type Track struct {
+	Channel int    // 0-based, or DownmixChannels
+	Label   string // speaker label of split channels
+}
+
+// Tracks returns the tracks to transcribe of a stream: every channel,
+// labelled, if split is set, else channel (which may be DownmixChannels)
+func Tracks(stream Stream, channel int, split bool) ([]Track, error) {
+	if !split {
+		if err := stream.CheckChannel(channel); err != nil {
+			return nil, err
+		}
+		return []Track{{Channel: channel}}, nil
+	}
+
+	if stream.Channels == 1 {
+		return []Track{{Channel: DownmixChannels}}, nil
+	}
+	tracks := make([]Track, stream.Channels)
+	for c := range tracks {
+		tracks[c] = Track{Channel: c, Label: ChannelLabel(c, stream.Channels)}
+	}
+	return tracks, nil
+}
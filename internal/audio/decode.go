@@ -0,0 +1,263 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/mewkiz/flac"
+)
+
+// SampleRate is the rate of the chunks handed to the recognizer
+const SampleRate = 16000
+
+// Containers decoded in-process; anything else goes through ffmpeg
+const (
+	containerWAV  = "wav"
+	containerFLAC = "flac"
+)
+
+// ChunkOptions selects the audio ExtractChunkWAV extracts and how it is
+// filtered
+type ChunkOptions struct {
+	Stream  int // audio stream, 0 is the first
+	Channel int // 0 is the first, or DownmixChannels
+	Filters Filters
+	Profile *FilterProfile // measured by AnalyzeFilters, nil to measure the chunk alone
+}
+
+// ExtractChunkWAV extracts a chunk of an audio stream, one channel or all
+// channels downmixed, to a 16 kHz mono 16-bit WAV file, running it through
+// the filters. WAV and FLAC files are decoded and resampled directly; other
+// formats need ffmpeg.
+func ExtractChunkWAV(audioFile, chunkFile string, start, duration float64, opts ChunkOptions) error {
+	wave, err := decodeChunk(audioFile, chunkFile, start, duration, opts)
+	if err != nil || wave == nil {
+		return err
+	}
+	wave.Samples = opts.Filters.Apply(wave.Samples, SampleRate, opts.Profile)
+	return WriteWave(chunkFile, wave)
+}
+
+// decodeChunk decodes a chunk as ExtractChunkWAV does, resampled to 16 kHz
+// but not filtered. When ffmpeg decodes it, it goes through chunkFile, and
+// without filters the wave is nil as chunkFile already holds the chunk.
+func decodeChunk(audioFile, chunkFile string, start, duration float64, opts ChunkOptions) (*Wave, error) {
+	var (
+		wave *Wave
+		err  error
+	)
+	switch container := sniffContainer(audioFile); {
+	case opts.Stream > 0:
+		// WAV and FLAC files have a single stream
+		err = errNotNative
+	case container == containerWAV:
+		wave, err = readWAVRange(audioFile, start, duration, opts.Channel)
+	case container == containerFLAC:
+		wave, err = readFLACRange(audioFile, start, duration, opts.Channel)
+	default:
+		err = errNotNative
+	}
+	if err != nil {
+		// Variants the decoders don't handle (ADPCM WAVs, say) may still
+		// be readable by ffmpeg
+		if ffErr := extractChunkFFmpeg(audioFile, chunkFile, start, duration, opts); ffErr != nil {
+			if err == errNotNative {
+				return nil, ffErr
+			}
+			return nil, fmt.Errorf("%w (ffmpeg fallback: %v)", err, ffErr)
+		}
+		if !opts.Filters.Enabled() {
+			return nil, nil
+		}
+		return ReadWave(chunkFile)
+	}
+
+	wave.Samples = Resample(wave.Samples, wave.SampleRate, SampleRate)
+	wave.SampleRate = SampleRate
+	return wave, nil
+}
+
+// errNotNative marks audio that is not decoded in-process
+var errNotNative = errors.New("not a WAV or FLAC file")
+
+// sniffContainer identifies WAV and FLAC files by their magic bytes, or
+// returns "" for anything else
+func sniffContainer(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	var magic [12]byte
+	if _, err := io.ReadFull(file, magic[:]); err != nil {
+		return ""
+	}
+	switch {
+	case (bytes.Equal(magic[0:4], []byte("RIFF")) || bytes.Equal(magic[0:4], []byte("RF64"))) && bytes.Equal(magic[8:12], []byte("WAVE")):
+		return containerWAV
+	case bytes.Equal(magic[0:4], []byte("fLaC")):
+		return containerFLAC
+	}
+	return ""
+}
+
+// openWAVData opens a WAV file at the start of its sample data, with
+// DataSize filled in from the file size if the header leaves it open
+func openWAVData(path string) (*os.File, WaveFormat, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, WaveFormat{}, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	format, err := ReadWaveHeader(file)
+	if err != nil {
+		file.Close()
+		return nil, format, err
+	}
+
+	offset, err := file.Seek(0, io.SeekCurrent)
+	if err == nil {
+		var info os.FileInfo
+		if info, err = file.Stat(); err == nil && (format.DataSize < 0 || offset+format.DataSize > info.Size()) {
+			// Open-ended or truncated data runs to the end of the file
+			format.DataSize = info.Size() - offset
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, format, err
+	}
+	return file, format, nil
+}
+
+// readWAVRange decodes duration seconds from start of one channel of a WAV
+// file, or of all channels downmixed
+func readWAVRange(path string, start, duration float64, channel int) (*Wave, error) {
+	file, format, err := openWAVData(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if channel >= format.Channels {
+		return nil, fmt.Errorf("channel %d out of range (file has %d)", channel+1, format.Channels)
+	}
+
+	first, count := frameRange(format.Frames(), format.SampleRate, start, duration)
+	blockAlign := int64(format.BlockAlign())
+	if _, err := file.Seek(first*blockAlign, io.SeekCurrent); err != nil {
+		return nil, fmt.Errorf("failed to seek to %.3fs: %w", start, err)
+	}
+
+	format.DataSize = count * blockAlign
+	return readSamples(file, format, channel)
+}
+
+// flacDuration reads the duration from the FLAC stream info, decoding the
+// file if the encoder did not record the sample count
+func flacDuration(path string) (float64, error) {
+	stream, err := flac.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open FLAC file: %w", err)
+	}
+	defer stream.Close()
+
+	samples := stream.Info.NSamples
+	if samples == 0 {
+		for {
+			frame, err := stream.ParseNext()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return 0, fmt.Errorf("failed to decode FLAC frame: %w", err)
+			}
+			samples += uint64(frame.BlockSize)
+		}
+	}
+	return float64(samples) / float64(stream.Info.SampleRate), nil
+}
+
+// readFLACRange decodes duration seconds from start of one channel of a
+// FLAC file, or of all channels downmixed
+func readFLACRange(path string, start, duration float64, channel int) (*Wave, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	stream, err := flac.NewSeek(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open FLAC file: %w", err)
+	}
+
+	info := stream.Info
+	if channel >= int(info.NChannels) {
+		return nil, fmt.Errorf("channel %d out of range (file has %d)", channel+1, info.NChannels)
+	}
+	total := int64(info.NSamples)
+	if total == 0 {
+		total = -1
+	}
+	first, count := frameRange(total, int(info.SampleRate), start, duration)
+	wave := &Wave{SampleRate: int(info.SampleRate), Samples: make([]float32, 0, count)}
+	if count == 0 {
+		return wave, nil
+	}
+
+	pos := uint64(0)
+	if first > 0 {
+		if pos, err = stream.Seek(uint64(first)); err != nil {
+			return nil, fmt.Errorf("failed to seek to %.3fs: %w", start, err)
+		}
+	}
+
+	scale := 1 / float32(int64(1)<<(info.BitsPerSample-1))
+	subframes := []int{channel}
+	if channel == DownmixChannels {
+		subframes = make([]int, info.NChannels)
+		for c := range subframes {
+			subframes[c] = c
+		}
+		scale /= float32(info.NChannels)
+	}
+	for int64(len(wave.Samples)) < count {
+		frame, err := stream.ParseNext()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode FLAC frame: %w", err)
+		}
+
+		for i := 0; i < int(frame.BlockSize) && int64(len(wave.Samples)) < count; i++ {
+			if pos+uint64(i) < uint64(first) {
+				continue
+			}
+			var sum int64
+			for _, c := range subframes {
+				sum += int64(frame.Subframes[c].Samples[i])
+			}
+			wave.Samples = append(wave.Samples, float32(sum)*scale)
+		}
+		pos += uint64(frame.BlockSize)
+	}
+	return wave, nil
+}
+
+// frameRange converts a time range to a first frame and a frame count,
+// clamped to total frames unless total is -1 (unknown)
+func frameRange(total int64, sampleRate int, start, duration float64) (first, count int64) {
+	first = int64(math.Round(start * float64(sampleRate)))
+	count = int64(math.Round(duration * float64(sampleRate)))
+	if total >= 0 {
+		first = min(first, total)
+		count = min(count, total-first)
+	}
+	return max(first, 0), max(count, 0)
+}
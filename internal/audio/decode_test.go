@@ -0,0 +1,31 @@
+package audio
+
+import "testing"
+
+func TestFrameRange(t *testing.T) {
+	tests := []struct {
+		name            string
+		total           int64
+		start, duration float64
+		first, count    int64
+	}{
+		{"inside", 16000, 0.25, 0.5, 4000, 8000},
+		{"whole", 16000, 0, 1, 0, 16000},
+		{"past the end", 16000, 0.75, 1, 12000, 4000},
+		{"starts after the end", 16000, 2, 1, 16000, 0},
+		{"negative start", 16000, -1, 0.5, 0, 8000},
+		{"negative duration", 16000, 0.5, -1, 8000, 0},
+		{"rounded", 16000, 1.0 / 3, 1.0 / 3, 5333, 5333},
+		{"unknown total", -1, 10, 5, 160000, 80000},
+		{"empty", 0, 0, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, count := frameRange(tt.total, 16000, tt.start, tt.duration)
+			if first != tt.first || count != tt.count {
+				t.Errorf("frameRange(%d, 16000, %v, %v) = %d, %d, want %d, %d",
+					tt.total, tt.start, tt.duration, first, count, tt.first, tt.count)
+			}
+		})
+	}
+}
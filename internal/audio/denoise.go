@@ -0,0 +1,198 @@
+package audio
+
+import (
+	"cmp"
+	"math"
+	"math/cmplx"
+	"slices"
+)
+
+// Spectral noise reduction over 32 ms frames at 16 kHz
+const (
+	denoiseFrame     = 512 // STFT frame length, a power of two
+	denoiseHop       = denoiseFrame / 2
+	denoiseBins      = denoiseFrame/2 + 1
+	denoiseNoiseFrac = 0.1 // quietest share of frames the noise is estimated from
+	denoiseOverSub   = 1.5 // noise over-subtraction factor
+	denoiseFloor     = 0.1 // minimum gain per bin (-20 dB), limits musical noise
+	denoiseSmoothing = 0.5 // weight of the previous frame's gain
+)
+
+// denoiseWindow is the sqrt-Hann window used for analysis and synthesis;
+// the two overlap-add to unity at 50% overlap
+var denoiseWindow = func() []float64 {
+	window := make([]float64, denoiseFrame)
+	for i := range window {
+		window[i] = math.Sin(math.Pi * float64(i) / denoiseFrame)
+	}
+	return window
+}()
+
+// stft returns the spectra of the windowed frames of samples, padded so the
+// first and last samples are covered twice. Frame f starts at sample
+// (f-1)*denoiseHop.
+func stft(samples []float32) [][]complex128 {
+	frames := (len(samples) + 2*denoiseHop - 1) / denoiseHop
+	padded := make([]float64, (frames+1)*denoiseHop)
+	for i, s := range samples {
+		padded[denoiseHop+i] = float64(s)
+	}
+
+	spectra := make([][]complex128, frames)
+	for f := range spectra {
+		buf := make([]complex128, denoiseFrame)
+		for i := range buf {
+			buf[i] = complex(padded[f*denoiseHop+i]*denoiseWindow[i], 0)
+		}
+		fft(buf, false)
+		spectra[f] = buf
+	}
+	return spectra
+}
+
+// denoise reduces stationary noise in mono samples in place by spectral
+// subtraction of noise, a power per bin as estimated by a noiseEstimator.
+// A nil noise spectrum leaves the samples alone.
+func denoise(samples []float32, noise []float64) {
+	if len(samples) < denoiseFrame || noise == nil {
+		return
+	}
+
+	spectra := stft(samples)
+	out := make([]float64, (len(spectra)+1)*denoiseHop)
+	gains := make([]float64, denoiseBins)
+	for i := range gains {
+		gains[i] = 1
+	}
+	for f, buf := range spectra {
+		for k := range denoiseBins {
+			gain := denoiseFloor
+			if power := sqrMag(buf[k]); power > 0 {
+				gain = max(1-denoiseOverSub*noise[k]/power, denoiseFloor)
+			}
+			gains[k] = denoiseSmoothing*gains[k] + (1-denoiseSmoothing)*gain
+			buf[k] *= complex(gains[k], 0)
+			if k > 0 && k < denoiseFrame/2 {
+				buf[denoiseFrame-k] = cmplx.Conj(buf[k])
+			}
+		}
+		fft(buf, true)
+		for i, c := range buf {
+			out[f*denoiseHop+i] += real(c) * denoiseWindow[i]
+		}
+	}
+
+	for i := range samples {
+		samples[i] = float32(out[denoiseHop+i])
+	}
+}
+
+// noiseEstimator estimates the noise spectrum as the mean power of the
+// quietest frames of the audio it is given, which may come in several
+// chunks. It keeps only the frames that can be among the quietest, so
+// long audio is not held in memory.
+type noiseEstimator struct {
+	keep   int // frames kept, denoiseNoiseFrac of those expected
+	seen   int
+	frames []noiseFrame
+}
+
+type noiseFrame struct {
+	energy float64
+	power  []float32 // per bin
+}
+
+// newNoiseEstimator returns an estimator for about the given number of
+// samples in all
+func newNoiseEstimator(samples int) *noiseEstimator {
+	return &noiseEstimator{keep: max(int(float64(samples/denoiseHop)*denoiseNoiseFrac), 1)}
+}
+
+// add takes the frames of samples, leaving out the edge frames that reach
+// into the padding
+func (e *noiseEstimator) add(samples []float32) {
+	if len(samples) < denoiseFrame {
+		return
+	}
+	spectra := stft(samples)
+	for f := 1; f*denoiseHop+denoiseFrame <= denoiseHop+len(samples); f++ {
+		frame := noiseFrame{power: make([]float32, denoiseBins)}
+		for k, c := range spectra[f][:denoiseBins] {
+			power := sqrMag(c)
+			frame.power[k] = float32(power)
+			frame.energy += power
+		}
+		e.frames = append(e.frames, frame)
+		e.seen++
+	}
+	if len(e.frames) > 2*e.keep {
+		e.prune()
+	}
+}
+
+// prune drops all but the quietest frames kept
+func (e *noiseEstimator) prune() {
+	slices.SortFunc(e.frames, func(a, b noiseFrame) int {
+		return cmp.Compare(a.energy, b.energy)
+	})
+	e.frames = e.frames[:min(e.keep, len(e.frames))]
+}
+
+// spectrum returns the noise power per bin: the mean over the quietest
+// denoiseNoiseFrac of the frames added, or nil if there were none
+func (e *noiseEstimator) spectrum() []float64 {
+	if len(e.frames) == 0 {
+		return nil
+	}
+	e.prune()
+	quiet := min(max(int(float64(e.seen)*denoiseNoiseFrac), 1), len(e.frames))
+	noise := make([]float64, denoiseBins)
+	for _, frame := range e.frames[:quiet] {
+		for k, p := range frame.power {
+			noise[k] += float64(p) / float64(quiet)
+		}
+	}
+	return noise
+}
+
+// fft transforms buf in place (radix-2, len(buf) a power of two); inverse
+// transforms are scaled by 1/n
+func fft(buf []complex128, inverse bool) {
+	n := len(buf)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j |= bit
+		if i < j {
+			buf[i], buf[j] = buf[j], buf[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for i := range size / 2 {
+				a, b := buf[start+i], buf[start+i+size/2]*w
+				buf[start+i], buf[start+i+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+
+	if inverse {
+		for i := range buf {
+			buf[i] /= complex(float64(n), 0)
+		}
+	}
+}
+
+func sqrMag(c complex128) float64 {
+	return real(c)*real(c) + imag(c)*imag(c)
+}
@@ -0,0 +1,114 @@
+package audio
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestFFTRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, n := range []int{1, 2, 8, denoiseFrame} {
+		in := make([]complex128, n)
+		for i := range in {
+			in[i] = complex(rng.Float64()*2-1, rng.Float64()*2-1)
+		}
+		buf := append([]complex128(nil), in...)
+		fft(buf, false)
+		fft(buf, true)
+		for i := range buf {
+			if cmplx.Abs(buf[i]-in[i]) > 1e-9 {
+				t.Errorf("n = %d: sample %d = %v after the round trip, want %v", n, i, buf[i], in[i])
+				break
+			}
+		}
+	}
+}
+
+func TestFFTTone(t *testing.T) {
+	// A cosine on bin 5 puts n/2 in bins 5 and n-5 and nothing elsewhere
+	const n, bin = 64, 5
+	buf := make([]complex128, n)
+	for i := range buf {
+		buf[i] = complex(math.Cos(2*math.Pi*bin*float64(i)/n), 0)
+	}
+	fft(buf, false)
+	for k, c := range buf {
+		want := 0.0
+		if k == bin || k == n-bin {
+			want = n / 2
+		}
+		if math.Abs(cmplx.Abs(c)-want) > 1e-9 {
+			t.Errorf("bin %d has magnitude %v, want %v", k, cmplx.Abs(c), want)
+		}
+	}
+}
+
+func TestDenoise(t *testing.T) {
+	// White noise throughout, with a 440 Hz tone in the second half
+	rng := rand.New(rand.NewPCG(3, 4))
+	samples := make([]float32, 4*SampleRate)
+	for i := range samples {
+		samples[i] = float32(rng.NormFloat64() * 0.01)
+		if i >= len(samples)/2 {
+			samples[i] += float32(0.3 * math.Sin(2*math.Pi*440*float64(i)/SampleRate))
+		}
+	}
+	noiseBefore := rms(samples[SampleRate/2 : 3*SampleRate/2])
+	toneBefore := rms(samples[5*SampleRate/2 : 7*SampleRate/2])
+
+	noise := newNoiseEstimator(len(samples))
+	noise.add(samples)
+	denoise(samples, noise.spectrum())
+
+	if got := db(rms(samples[SampleRate/2:3*SampleRate/2]) / noiseBefore); got > -10 {
+		t.Errorf("noise changed by %.1f dB, want at least 10 dB less", got)
+	}
+	if got := db(rms(samples[5*SampleRate/2:7*SampleRate/2]) / toneBefore); math.Abs(got) > 0.5 {
+		t.Errorf("tone changed by %.1f dB, want within 0.5 dB", got)
+	}
+}
+
+func TestNoiseEstimatorChunks(t *testing.T) {
+	// The quietest frames are all in the first chunk, which an estimate
+	// over the chunks must find as a single pass does
+	rng := rand.New(rand.NewPCG(5, 6))
+	samples := make([]float32, 6*SampleRate)
+	for i := range samples {
+		level := 0.2
+		if i < SampleRate {
+			level = 0.01
+		}
+		samples[i] = float32(rng.NormFloat64() * level)
+	}
+
+	whole := newNoiseEstimator(len(samples))
+	whole.add(samples)
+	chunked := newNoiseEstimator(len(samples))
+	for start := 0; start < len(samples); start += SampleRate {
+		chunked.add(samples[start : start+SampleRate])
+	}
+
+	want, got := whole.spectrum(), chunked.spectrum()
+	var wantSum, gotSum float64
+	for k := range want {
+		wantSum += want[k]
+		gotSum += got[k]
+	}
+	if math.Abs(db(math.Sqrt(gotSum/wantSum))) > 1 {
+		t.Errorf("chunked noise power %v, want about %v", gotSum, wantSum)
+	}
+}
+
+func rms(samples []float32) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func db(ratio float64) float64 {
+	return 20 * math.Log10(ratio)
+}
@@ -1,40 +1,205 @@
 package audio
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 )
 
-// ProbeDuration returns the duration of an audio file in seconds
-func ProbeDuration(audioFile string) (float64, error) {
+// requireTool checks that an external tool is installed, naming it and the
+// file that needs it otherwise
+func requireTool(name, audioFile string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s not found: it is needed to read %s (WAV and FLAC are read without it)", name, filepath.Base(audioFile))
+	}
+	return nil
+}
+
+// ffprobeOutput is the part of ffprobe's JSON output probeFFmpeg reads
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType  string `json:"codec_type"`
+		CodecName  string `json:"codec_name"`
+		Channels   int    `json:"channels"`
+		SampleRate string `json:"sample_rate"`
+		Duration   string `json:"duration"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+	} `json:"format"`
+}
+
+// probeFFmpeg reads the container and audio streams of a file using
+// ffprobe
+func probeFFmpeg(audioFile string) (*Probe, error) {
+	if err := requireTool("ffprobe", audioFile); err != nil {
+		return nil, err
+	}
 	cmd := exec.Command("ffprobe",
 		"-v", "error",
-		"-show_entries", "format=duration",
-		"-of", "default=noprint_wrappers=1:nokey=1",
+		"-show_entries", "format=format_name,duration:stream=codec_type,codec_name,channels,sample_rate,duration",
+		"-of", "json",
 		audioFile,
 	)
 	out, err := cmd.Output()
 	if err != nil {
-		return 0, err
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("%s is not a readable media file: %s", filepath.Base(audioFile), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
 	}
-	return strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+
+	var parsed ffprobeOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	// Some containers (Matroska, say) only time the file, not its streams
+	duration, _ := strconv.ParseFloat(parsed.Format.Duration, 64)
+	probe := &Probe{
+		File:      audioFile,
+		Container: strings.Split(parsed.Format.FormatName, ",")[0],
+		Duration:  duration,
+	}
+	for _, s := range parsed.Streams {
+		if s.CodecType != "audio" {
+			continue
+		}
+		stream := Stream{
+			Index:    len(probe.Streams),
+			Codec:    s.CodecName,
+			Channels: s.Channels,
+			Duration: duration,
+		}
+		stream.SampleRate, _ = strconv.Atoi(s.SampleRate)
+		if d, err := strconv.ParseFloat(s.Duration, 64); err == nil && d > 0 {
+			stream.Duration = d
+		}
+		probe.Streams = append(probe.Streams, stream)
+	}
+	return probe, nil
 }
 
-// ExtractChunkWAV extracts a chunk from an audio file to a WAV file
-func ExtractChunkWAV(audioFile, chunkFile string, start, duration float64) error {
-	cmd := exec.Command("ffmpeg",
+// extractChunkFFmpeg extracts a chunk of one channel, or of all channels
+// downmixed, of an audio stream to a WAV file using ffmpeg
+func extractChunkFFmpeg(audioFile, chunkFile string, start, duration float64, opts ChunkOptions) error {
+	if err := requireTool("ffmpeg", audioFile); err != nil {
+		return err
+	}
+
+	mix := []string{"-ac", "1"}
+	if opts.Channel != DownmixChannels {
+		mix = []string{"-af", fmt.Sprintf("pan=mono|c0=c%d", opts.Channel)}
+	}
+
+	args := []string{
 		"-ss", fmt.Sprintf("%.3f", start),
 		"-t", fmt.Sprintf("%.3f", duration),
 		"-i", audioFile,
+		"-map", fmt.Sprintf("0:a:%d", opts.Stream),
 		"-vn",
-		"-ar", "16000",
-		"-ac", "1",
+		"-ar", strconv.Itoa(SampleRate),
+	}
+	args = append(args, mix...)
+	args = append(args,
 		"-acodec", "pcm_s16le",
 		"-y",
 		chunkFile,
 	)
+	cmd := exec.Command("ffmpeg", args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg: %s", out)
+	}
+	return nil
+}
+
+// subtitleCodecs maps output containers to the text subtitle codec they
+// carry; subtitles are converted from WebVTT input
+var subtitleCodecs = map[string]string{
+	".mkv":  "srt",
+	".mp4":  "mov_text",
+	".m4v":  "mov_text",
+	".mov":  "mov_text",
+	".webm": "webvtt",
+}
+
+// SubtitleContainers lists the extensions EmbedSubtitles can write
+func SubtitleContainers() []string {
+	exts := make([]string, 0, len(subtitleCodecs))
+	for ext := range subtitleCodecs {
+		exts = append(exts, ext)
+	}
+	slices.Sort(exts)
+	return exts
+}
+
+// EmbedSubtitles copies the video and audio streams of input into output
+// with a WebVTT subtitle file as a soft subtitle track, without
+// re-encoding. language is an ISO 639-2 code. Existing subtitle tracks are
+// not copied.
+func EmbedSubtitles(input, subtitles, output, language string) error {
+	codec, ok := subtitleCodecs[strings.ToLower(filepath.Ext(output))]
+	if !ok {
+		return fmt.Errorf("unsupported subtitle container %q (valid: %s)", filepath.Ext(output), strings.Join(SubtitleContainers(), ", "))
+	}
+
+	cmd := exec.Command("ffmpeg",
+		"-v", "error",
+		"-i", input,
+		"-i", subtitles,
+		"-map", "0:v?",
+		"-map", "0:a?",
+		"-map", "1:0",
+		"-c", "copy",
+		"-c:s", codec,
+		"-metadata:s:s:0", "language="+language,
+		"-y",
+		output,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg: %s", out)
+	}
+	return nil
+}
+
+// BurnSubtitles renders an ASS subtitle file into the video of input,
+// re-encoding the video and copying the audio. language is set as the
+// audio track's language. input may be a URL ffmpeg reads itself.
+func BurnSubtitles(input, subtitles, output, language string) error {
+	// ffmpeg runs in another directory, so relative paths are made absolute
+	var err error
+	if !strings.Contains(input, "://") {
+		input, err = filepath.Abs(input)
+		if err != nil {
+			return err
+		}
+	}
+	output, err = filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+
+	// Run from the subtitle file's directory so the filter argument needs
+	// no path escaping
+	cmd := exec.Command("ffmpeg",
+		"-v", "error",
+		"-i", input,
+		"-map", "0:v:0",
+		"-map", "0:a?",
+		"-vf", "subtitles="+filepath.Base(subtitles),
+		"-c:a", "copy",
+		"-metadata:s:a:0", "language="+language,
+		"-y",
+		output,
+	)
+	cmd.Dir = filepath.Dir(subtitles)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("ffmpeg: %s", out)
 	}
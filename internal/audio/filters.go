@@ -0,0 +1,217 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LoudnessTarget is the integrated loudness chunks are normalized to, in
+// LUFS (EBU R128)
+const LoudnessTarget = -23.0
+
+// Filters is a preprocessing chain applied to each chunk once it is
+// resampled to 16 kHz mono, in field order. The noise spectrum and loudness
+// are measured over the whole track (see AnalyzeFilters).
+type Filters struct {
+	HighPass float64 // cutoff in Hz, 0 disables
+	Denoise  bool    // spectral noise reduction
+	Loudnorm bool    // EBU R128 loudness normalization to LoudnessTarget
+}
+
+// Presets are the named filter chains of --preprocess
+var Presets = map[string]Filters{
+	"none":      {},
+	"normalize": {Loudnorm: true},
+	"phone":     {HighPass: 100, Loudnorm: true},
+	"noisy":     {HighPass: 80, Denoise: true, Loudnorm: true},
+}
+
+// PresetNames lists the presets in order of strength
+var PresetNames = []string{"none", "normalize", "phone", "noisy"}
+
+// ParsePreset returns the filters of a named preset; "" is none
+func ParsePreset(name string) (Filters, error) {
+	if name == "" {
+		return Filters{}, nil
+	}
+	f, ok := Presets[strings.ToLower(name)]
+	if !ok {
+		return Filters{}, fmt.Errorf("unknown preprocess preset %q (valid: %s)", name, strings.Join(PresetNames, ", "))
+	}
+	return f, nil
+}
+
+// ResolveFilters returns the filters of a preset with explicitly set
+// filters added on top: a high-pass cutoff replaces the preset's
+func ResolveFilters(preset string, explicit Filters) (Filters, error) {
+	f, err := ParsePreset(preset)
+	if err != nil {
+		return f, err
+	}
+	if explicit.HighPass != 0 {
+		f.HighPass = explicit.HighPass
+	}
+	f.Denoise = f.Denoise || explicit.Denoise
+	f.Loudnorm = f.Loudnorm || explicit.Loudnorm
+	return f, f.Validate()
+}
+
+// Validate checks that the filters can be applied to 16 kHz audio
+func (f Filters) Validate() error {
+	if f.HighPass < 0 || f.HighPass >= SampleRate/2 {
+		return fmt.Errorf("high-pass cutoff must be between 0 and %d Hz, got %g", SampleRate/2, f.HighPass)
+	}
+	return nil
+}
+
+// Enabled reports whether any filter is set
+func (f Filters) Enabled() bool {
+	return f != Filters{}
+}
+
+// String describes the chain in order, e.g. "highpass=80,denoise,loudnorm=-23",
+// or returns "" if no filter is set
+func (f Filters) String() string {
+	var chain []string
+	if f.HighPass > 0 {
+		chain = append(chain, "highpass="+strconv.FormatFloat(f.HighPass, 'f', -1, 64))
+	}
+	if f.Denoise {
+		chain = append(chain, "denoise")
+	}
+	if f.Loudnorm {
+		chain = append(chain, "loudnorm="+strconv.FormatFloat(LoudnessTarget, 'f', -1, 64))
+	}
+	return strings.Join(chain, ",")
+}
+
+// Apply runs the chain over mono samples in place and returns them. The
+// noise spectrum and loudness gain come from profile, as measured over the
+// whole track by AnalyzeFilters; with a nil profile they are measured over
+// samples alone.
+func (f Filters) Apply(samples []float32, sampleRate int, profile *FilterProfile) []float32 {
+	if f.HighPass > 0 {
+		highPass(samples, sampleRate, f.HighPass)
+	}
+	if f.Denoise {
+		if profile != nil {
+			denoise(samples, profile.Noise)
+		} else {
+			noise := newNoiseEstimator(len(samples))
+			noise.add(samples)
+			denoise(samples, noise.spectrum())
+		}
+	}
+	if f.Loudnorm {
+		var gain float64
+		if profile != nil {
+			gain = profile.Gain
+		} else {
+			meter := loudnessMeter{sampleRate: sampleRate}
+			meter.add(samples)
+			gain = meter.gain(LoudnessTarget)
+		}
+		for i := range samples {
+			samples[i] *= float32(gain)
+		}
+	}
+	return samples
+}
+
+// FilterProfile holds what the filters measure over a whole track, so that
+// every chunk of it is denoised and normalized alike
+type FilterProfile struct {
+	Noise []float64 // noise power per denoising bin, nil to leave noise alone
+	Gain  float64   // loudness normalization gain
+}
+
+// AnalyzeFilters measures the noise spectrum and loudness of a track, the
+// stream and channel of opts, over the chunks between boundaries, for
+// ExtractChunkWAV to apply to each chunk. It decodes the chunks once, or
+// twice with both denoising and normalization, as loudness is measured on
+// the denoised audio. It returns nil if the filters measure nothing.
+func AnalyzeFilters(audioFile string, boundaries []float64, opts ChunkOptions) (*FilterProfile, error) {
+	f := opts.Filters
+	if !f.Denoise && !f.Loudnorm || len(boundaries) < 2 {
+		return nil, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "chough-analyze-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	chunkFile := filepath.Join(tmpDir, "chunk.wav")
+
+	// pass hands every chunk, high-passed, to measure
+	pass := func(measure func(samples []float32)) error {
+		for i := 0; i < len(boundaries)-1; i++ {
+			wave, err := decodeChunk(audioFile, chunkFile, boundaries[i], boundaries[i+1]-boundaries[i], opts)
+			if err != nil {
+				return fmt.Errorf("failed to analyze chunk %d: %w", i+1, err)
+			}
+			if f.HighPass > 0 {
+				highPass(wave.Samples, SampleRate, f.HighPass)
+			}
+			measure(wave.Samples)
+		}
+		return nil
+	}
+
+	profile := &FilterProfile{Gain: 1}
+	if f.Denoise {
+		noise := newNoiseEstimator(int((boundaries[len(boundaries)-1] - boundaries[0]) * SampleRate))
+		if err := pass(noise.add); err != nil {
+			return nil, err
+		}
+		profile.Noise = noise.spectrum()
+	}
+	if f.Loudnorm {
+		meter := loudnessMeter{sampleRate: SampleRate}
+		err := pass(func(samples []float32) {
+			denoise(samples, profile.Noise)
+			meter.add(samples)
+		})
+		if err != nil {
+			return nil, err
+		}
+		profile.Gain = meter.gain(LoudnessTarget)
+	}
+	return profile, nil
+}
+
+// biquad is a second-order IIR filter, normalized so that a0 is 1
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+}
+
+// run filters samples in place (direct form I)
+func (q biquad) run(samples []float32) {
+	var x1, x2, y1, y2 float64
+	for i, s := range samples {
+		x := float64(s)
+		y := q.b0*x + q.b1*x1 + q.b2*x2 - q.a1*y1 - q.a2*y2
+		x2, x1 = x1, x
+		y2, y1 = y1, y
+		samples[i] = float32(y)
+	}
+}
+
+// highPass applies a second-order Butterworth high-pass filter
+func highPass(samples []float32, sampleRate int, cutoff float64) {
+	w0 := 2 * math.Pi * cutoff / float64(sampleRate)
+	alpha := math.Sin(w0) / math.Sqrt2 // Q = 1/√2
+	cos := math.Cos(w0)
+	a0 := 1 + alpha
+	biquad{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}.run(samples)
+}
@@ -0,0 +1,76 @@
+package audio
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestHighPass(t *testing.T) {
+	tests := []struct {
+		cutoff, freq float64
+		want         float64 // gain in dB
+		tolerance    float64
+	}{
+		// A second-order Butterworth falls 12 dB per octave below the
+		// cutoff and is 3 dB down at it
+		{100, 25, -24.1, 0.5},
+		{100, 50, -12.3, 0.5},
+		{100, 100, -3, 0.2},
+		{100, 1000, 0, 0.1},
+		{80, 20, -24.1, 0.5},
+		{80, 4000, 0, 0.1},
+	}
+	for _, tt := range tests {
+		samples := sine(tt.freq, 0.5, 3)
+		before := rms(samples[SampleRate:])
+		highPass(samples, SampleRate, tt.cutoff)
+
+		// Skip the first second, where the filter settles
+		if got := db(rms(samples[SampleRate:]) / before); math.Abs(got-tt.want) > tt.tolerance {
+			t.Errorf("%g Hz high-pass: %g Hz changed by %.2f dB, want %.1f dB", tt.cutoff, tt.freq, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeFilters(t *testing.T) {
+	// Speech-level tone, then a much quieter one: measured per chunk each
+	// half would be normalized on its own
+	samples := append(sine(997, lufsSine(-18), 4), sine(997, lufsSine(-38), 4)...)
+	dir := t.TempDir()
+	audioFile := filepath.Join(dir, "in.wav")
+	if err := WriteWave(audioFile, &Wave{Samples: samples, SampleRate: SampleRate}); err != nil {
+		t.Fatal(err)
+	}
+
+	boundaries := []float64{0, 4, 8}
+	opts := ChunkOptions{Channel: DownmixChannels, Filters: Filters{Loudnorm: true}}
+	profile, err := AnalyzeFilters(audioFile, boundaries, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := math.Pow(10, (LoudnessTarget+18)/20); math.Abs(db(profile.Gain/want)) > 0.2 {
+		t.Errorf("Gain = %v, want about %v", profile.Gain, want)
+	}
+
+	// Both chunks get the track's gain
+	opts.Profile = profile
+	for i := range len(boundaries) - 1 {
+		chunkFile := filepath.Join(dir, "chunk.wav")
+		if err := ExtractChunkWAV(audioFile, chunkFile, boundaries[i], boundaries[i+1]-boundaries[i], opts); err != nil {
+			t.Fatal(err)
+		}
+		chunk, err := ReadWave(chunkFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		in := samples[i*4*SampleRate : (i+1)*4*SampleRate]
+		if got := rms(chunk.Samples) / rms(in); math.Abs(db(got/profile.Gain)) > 0.1 {
+			t.Errorf("chunk %d scaled by %v, want %v", i+1, got, profile.Gain)
+		}
+	}
+
+	if profile, err := AnalyzeFilters(audioFile, boundaries, ChunkOptions{Filters: Filters{HighPass: 80}}); profile != nil || err != nil {
+		t.Errorf("AnalyzeFilters(high-pass only) = %v, %v, want nil", profile, err)
+	}
+}
@@ -0,0 +1,32 @@
+package audio
+
+// trackLanguages maps ISO 639-1 codes, and Whisper's few others, to the
+// ISO 639-2/B codes Matroska and MP4 tag tracks with
+var trackLanguages = map[string]string{
+	"af": "afr", "am": "amh", "ar": "ara", "as": "asm", "az": "aze", "ba": "bak",
+	"be": "bel", "bg": "bul", "bn": "ben", "bo": "tib", "br": "bre", "bs": "bos",
+	"ca": "cat", "cs": "cze", "cy": "wel", "da": "dan", "de": "ger", "el": "gre",
+	"en": "eng", "es": "spa", "et": "est", "eu": "baq", "fa": "per", "fi": "fin",
+	"fo": "fao", "fr": "fre", "gl": "glg", "gu": "guj", "ha": "hau", "haw": "haw",
+	"he": "heb", "hi": "hin", "hr": "hrv", "ht": "hat", "hu": "hun", "hy": "arm",
+	"id": "ind", "is": "ice", "it": "ita", "ja": "jpn", "jv": "jav", "jw": "jav",
+	"ka": "geo", "kk": "kaz", "km": "khm", "kn": "kan", "ko": "kor", "la": "lat",
+	"lb": "ltz", "ln": "lin", "lo": "lao", "lt": "lit", "lv": "lav", "mg": "mlg",
+	"mi": "mao", "mk": "mac", "ml": "mal", "mn": "mon", "mr": "mar", "ms": "may",
+	"mt": "mlt", "my": "bur", "ne": "nep", "nl": "dut", "nn": "nno", "no": "nor",
+	"oc": "oci", "pa": "pan", "pl": "pol", "ps": "pus", "pt": "por", "ro": "rum",
+	"ru": "rus", "sa": "san", "sd": "snd", "si": "sin", "sk": "slo", "sl": "slv",
+	"sn": "sna", "so": "som", "sq": "alb", "sr": "srp", "su": "sun", "sv": "swe",
+	"sw": "swa", "ta": "tam", "te": "tel", "tg": "tgk", "th": "tha", "tk": "tuk",
+	"tl": "tgl", "tr": "tur", "tt": "tat", "uk": "ukr", "ur": "urd", "uz": "uzb",
+	"vi": "vie", "yi": "yid", "yo": "yor", "yue": "chi", "zh": "chi",
+}
+
+// TrackLanguage returns the ISO 639-2 code for an ISO 639-1 language, as
+// EmbedSubtitles and BurnSubtitles take, or "und" if it is unknown
+func TrackLanguage(lang string) string {
+	if code, ok := trackLanguages[lang]; ok {
+		return code
+	}
+	return "und"
+}
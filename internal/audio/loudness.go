@@ -0,0 +1,138 @@
+package audio
+
+import "math"
+
+// Gating of the integrated loudness measurement (EBU R128 / ITU-R BS.1770)
+const (
+	loudnessBlock        = 0.4   // block length in seconds
+	loudnessStep         = 0.1   // block hop in seconds (75% overlap)
+	loudnessAbsoluteGate = -70.0 // LUFS
+	loudnessRelativeGate = -10.0 // LU below the absolute-gated loudness
+)
+
+// peakCeiling is the sample peak normalization may not exceed (-1 dBFS)
+var peakCeiling = math.Pow(10, -1.0/20)
+
+// kWeighting returns the two stages of the BS.1770 K-weighting filter for a
+// sample rate: a high shelf modelling the head, and a high-pass
+func kWeighting(sampleRate int) (shelf, rlb biquad) {
+	fs := float64(sampleRate)
+
+	f0, gain, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / fs)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf = biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / fs)
+	a0 = 1 + k/q + k*k
+	rlb = biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return shelf, rlb
+}
+
+// loudnessMeter gathers the gating blocks and sample peak of mono audio,
+// which may come in several chunks, to measure the integrated loudness of
+// all of it. Blocks do not span chunks.
+type loudnessMeter struct {
+	sampleRate int
+	powers     []float64 // K-weighted mean square per block
+	peak       float32
+}
+
+// add measures the blocks and peak of samples
+func (m *loudnessMeter) add(samples []float32) {
+	for _, s := range samples {
+		m.peak = max(m.peak, float32(math.Abs(float64(s))))
+	}
+
+	block := int(loudnessBlock * float64(m.sampleRate))
+	step := int(loudnessStep * float64(m.sampleRate))
+	if len(samples) < block {
+		return
+	}
+
+	weighted := make([]float32, len(samples))
+	copy(weighted, samples)
+	shelf, rlb := kWeighting(m.sampleRate)
+	shelf.run(weighted)
+	rlb.run(weighted)
+
+	for start := 0; start+block <= len(weighted); start += step {
+		var sum float64
+		for _, s := range weighted[start : start+block] {
+			sum += float64(s) * float64(s)
+		}
+		m.powers = append(m.powers, sum/float64(block))
+	}
+}
+
+// loudness returns the integrated loudness in LUFS, or -Inf for audio
+// shorter than one block or gated out entirely
+func (m *loudnessMeter) loudness() float64 {
+	gated := func(threshold float64) float64 {
+		var sum float64
+		n := 0
+		for _, p := range m.powers {
+			if blockLoudness(p) > threshold {
+				sum += p
+				n++
+			}
+		}
+		if n == 0 {
+			return 0
+		}
+		return sum / float64(n)
+	}
+
+	absolute := gated(loudnessAbsoluteGate)
+	if absolute == 0 {
+		return math.Inf(-1)
+	}
+	relative := gated(blockLoudness(absolute) + loudnessRelativeGate)
+	if relative == 0 {
+		return math.Inf(-1)
+	}
+	return blockLoudness(relative)
+}
+
+// gain returns the gain that brings the audio to target LUFS, as far as
+// the sample peak stays under peakCeiling. Silence and audio too short to
+// measure get a gain of 1.
+func (m *loudnessMeter) gain(target float64) float64 {
+	loudness := m.loudness()
+	if math.IsInf(loudness, -1) {
+		return 1
+	}
+	gain := math.Pow(10, (target-loudness)/20)
+	if m.peak > 0 {
+		gain = min(gain, peakCeiling/float64(m.peak))
+	}
+	return gain
+}
+
+// integratedLoudness measures mono samples in LUFS, or returns -Inf for
+// audio shorter than one block or gated out entirely
+func integratedLoudness(samples []float32, sampleRate int) float64 {
+	m := loudnessMeter{sampleRate: sampleRate}
+	m.add(samples)
+	return m.loudness()
+}
+
+// blockLoudness converts a K-weighted mean square to LUFS
+func blockLoudness(power float64) float64 {
+	return -0.691 + 10*math.Log10(power)
+}
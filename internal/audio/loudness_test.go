@@ -0,0 +1,96 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// sine returns seconds of a sine at 16 kHz
+func sine(freq, amplitude, seconds float64) []float32 {
+	samples := make([]float32, int(seconds*SampleRate))
+	for i := range samples {
+		samples[i] = float32(amplitude * math.Sin(2*math.Pi*freq*float64(i)/SampleRate))
+	}
+	return samples
+}
+
+// lufsSine is the amplitude of a 997 Hz sine measuring lufs; a full-scale
+// one measures -3.01 LUFS (ITU-R BS.1770)
+func lufsSine(lufs float64) float64 {
+	return math.Pow(10, (lufs+3.01)/20)
+}
+
+func TestIntegratedLoudness(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float32
+		want    float64
+	}{
+		{"-20 LUFS sine", sine(997, lufsSine(-20), 5), -20},
+		{"-23 LUFS sine", sine(997, lufsSine(-23), 5), -23},
+		{"full scale sine", sine(997, 1, 5), -3.01},
+		{"silence", make([]float32, 5*SampleRate), math.Inf(-1)},
+		{"below the absolute gate", sine(997, lufsSine(-75), 5), math.Inf(-1)},
+		{"shorter than a block", sine(997, 0.5, 0.3), math.Inf(-1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := integratedLoudness(tt.samples, SampleRate)
+			if math.IsInf(tt.want, -1) {
+				if !math.IsInf(got, -1) {
+					t.Errorf("integratedLoudness = %v, want -Inf", got)
+				}
+				return
+			}
+			if math.Abs(got-tt.want) > 0.1 {
+				t.Errorf("integratedLoudness = %.2f LUFS, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoudnessGain(t *testing.T) {
+	tests := []struct {
+		name      string
+		amplitude float64
+		spike     float32 // a single sample's peak, 0 for none
+		want      float64 // LUFS after normalization
+	}{
+		{"quiet", lufsSine(-35), 0, LoudnessTarget},
+		{"loud", lufsSine(-14), 0, LoudnessTarget},
+		// A 0.9 spike leaves no headroom below -1 dBFS, so the gain is
+		// checked instead
+		{"peak limited", lufsSine(-30), 0.9, 0},
+		{"silence", 0, 0, math.Inf(-1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := sine(997, tt.amplitude, 5)
+			if tt.spike != 0 {
+				samples[len(samples)/2] = tt.spike
+			}
+			meter := loudnessMeter{sampleRate: SampleRate}
+			meter.add(samples)
+			gain := meter.gain(LoudnessTarget)
+			for i := range samples {
+				samples[i] *= float32(gain)
+			}
+
+			switch {
+			case tt.spike != 0:
+				if want := peakCeiling / float64(tt.spike); math.Abs(gain-want) > 1e-9 {
+					t.Errorf("gain = %v, want %v", gain, want)
+				}
+				return
+			case math.IsInf(tt.want, -1):
+				if gain != 1 {
+					t.Errorf("gain = %v, want 1", gain)
+				}
+				return
+			}
+			if got := integratedLoudness(samples, SampleRate); math.Abs(got-tt.want) > 0.1 {
+				t.Errorf("normalized to %.2f LUFS, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
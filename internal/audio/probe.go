@@ -0,0 +1,128 @@
+package audio
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mewkiz/flac"
+)
+
+// Probe describes an input file and its audio streams
+type Probe struct {
+	File      string
+	Container string  // wav, flac, or ffprobe's format name (mov, matroska, ...)
+	Duration  float64 // seconds
+	Streams   []Stream
+}
+
+// Stream describes one audio stream of a file
+type Stream struct {
+	Index      int    // among the audio streams, 0 is the first (ffmpeg's 0:a:N)
+	Codec      string // e.g. pcm_s16le, flac, aac
+	Channels   int
+	SampleRate int
+	Duration   float64 // seconds, the file's if the stream has none
+}
+
+// ProbeFile reads the container and audio streams of a file. WAV and FLAC
+// files are read directly; other formats need ffprobe.
+func ProbeFile(audioFile string) (*Probe, error) {
+	switch sniffContainer(audioFile) {
+	case containerWAV:
+		if p, err := probeWAV(audioFile); err == nil {
+			return p, nil
+		}
+	case containerFLAC:
+		if p, err := probeFLAC(audioFile); err == nil {
+			return p, nil
+		}
+	}
+	return probeFFmpeg(audioFile)
+}
+
+// Stream returns audio stream n, or an error if the file has no such
+// stream or it cannot be transcribed
+func (p *Probe) Stream(n int) (Stream, error) {
+	name := filepath.Base(p.File)
+	switch {
+	case len(p.Streams) == 0:
+		return Stream{}, fmt.Errorf("%s has no audio stream", name)
+	case n < 0 || n >= len(p.Streams):
+		return Stream{}, fmt.Errorf("audio stream %d out of range (%s has %d, numbered from 0)", n, name, len(p.Streams))
+	}
+
+	s := p.Streams[n]
+	switch {
+	case s.Channels <= 0:
+		return s, fmt.Errorf("audio stream %d of %s has no channels", n, name)
+	case s.SampleRate <= 0:
+		return s, fmt.Errorf("audio stream %d of %s has no sample rate", n, name)
+	case s.Duration <= 0:
+		return s, fmt.Errorf("audio stream %d of %s is empty", n, name)
+	}
+	return s, nil
+}
+
+// String summarizes the stream, e.g. "pcm_s16le 2ch 44100Hz"
+func (s Stream) String() string {
+	return fmt.Sprintf("%s %dch %dHz", s.Codec, s.Channels, s.SampleRate)
+}
+
+// probeWAV reads the WAV header
+func probeWAV(path string) (*Probe, error) {
+	file, format, err := openWAVData(path)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+
+	duration := format.Duration()
+	return &Probe{
+		File:      path,
+		Container: containerWAV,
+		Duration:  duration,
+		Streams: []Stream{{
+			Codec:      wavCodec(format),
+			Channels:   format.Channels,
+			SampleRate: format.SampleRate,
+			Duration:   duration,
+		}},
+	}, nil
+}
+
+// wavCodec names the sample format of a WAV file as ffmpeg does
+func wavCodec(format WaveFormat) string {
+	switch {
+	case format.Float:
+		return fmt.Sprintf("pcm_f%dle", format.BitsPerSample)
+	case format.BitsPerSample == 8:
+		return "pcm_u8"
+	}
+	return fmt.Sprintf("pcm_s%dle", format.BitsPerSample)
+}
+
+// probeFLAC reads the FLAC stream info
+func probeFLAC(path string) (*Probe, error) {
+	stream, err := flac.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open FLAC file: %w", err)
+	}
+	info := *stream.Info
+	stream.Close()
+
+	duration, err := flacDuration(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Probe{
+		File:      path,
+		Container: containerFLAC,
+		Duration:  duration,
+		Streams: []Stream{{
+			Codec:      containerFLAC,
+			Channels:   int(info.NChannels),
+			SampleRate: int(info.SampleRate),
+			Duration:   duration,
+		}},
+	}, nil
+}
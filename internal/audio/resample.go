@@ -0,0 +1,138 @@
+package audio
+
+import (
+	"math"
+	"sync"
+)
+
+// resampleZeroCrossings is the half width of the resampling filter, in
+// zero crossings of its sinc
+const resampleZeroCrossings = 16
+
+// maxFilterPhases caps the kernels tabulated per rate pair. Rates whose
+// ratio needs more use the nearest phase, off by at most 1/2048 of an input
+// sample.
+const maxFilterPhases = 1024
+
+// filterBanks caches a filterBank per [from, to] rate pair
+var filterBanks sync.Map
+
+// Resample converts mono samples between sample rates with a
+// Hann-windowed sinc filter, low-passed below the lower of the two
+// Nyquist frequencies
+func Resample(samples []float32, from, to int) []float32 {
+	if from == to || from <= 0 || to <= 0 || len(samples) == 0 {
+		return samples
+	}
+
+	bank := filterBankFor(from, to)
+	out := make([]float32, int(math.Round(float64(len(samples))*float64(to)/float64(from))))
+
+	for i := range out {
+		// The output sample sits at pos/up input samples, between base
+		// and the next one
+		pos := int64(i) * int64(bank.down)
+		base := int(pos / int64(bank.up))
+		phase := int((pos%int64(bank.up)*int64(bank.phases) + int64(bank.up)/2) / int64(bank.up))
+		if phase == bank.phases {
+			phase = 0
+			base++
+		}
+		kernel := bank.kernels[phase*bank.taps : (phase+1)*bank.taps]
+		first := base + bank.first
+
+		if first >= 0 && first+bank.taps <= len(samples) {
+			var sum float64
+			for k, w := range kernel {
+				sum += w * float64(samples[first+k])
+			}
+			out[i] = float32(sum)
+			continue
+		}
+
+		// Normalizing by the summed weights keeps unity gain at the edges,
+		// where the filter is cut short
+		var sum, weight float64
+		for k, w := range kernel {
+			if j := first + k; j >= 0 && j < len(samples) {
+				sum += w * float64(samples[j])
+				weight += w
+			}
+		}
+		if weight != 0 {
+			out[i] = float32(sum / weight)
+		}
+	}
+	return out
+}
+
+// filterBank holds the resampling filter for one rate pair, tabulated for
+// each fractional input position an output sample can fall on (a polyphase
+// filter bank), so resampling only multiplies and adds
+type filterBank struct {
+	up, down int // to and from divided by their greatest common divisor
+	phases   int // fractional positions tabulated
+	first    int // offset of the first tap from the input sample at or before the output
+	taps     int
+	kernels  []float64 // phases × taps, each summing to 1
+}
+
+func filterBankFor(from, to int) *filterBank {
+	key := [2]int{from, to}
+	if bank, ok := filterBanks.Load(key); ok {
+		return bank.(*filterBank)
+	}
+	bank, _ := filterBanks.LoadOrStore(key, newFilterBank(from, to))
+	return bank.(*filterBank)
+}
+
+func newFilterBank(from, to int) *filterBank {
+	g := gcd(from, to)
+	b := &filterBank{up: to / g, down: from / g}
+	b.phases = min(b.up, maxFilterPhases)
+
+	// Cutoff relative to the input Nyquist frequency; downsampling widens
+	// the filter in input samples accordingly
+	cutoff := math.Min(1, float64(to)/float64(from))
+	halfWidth := resampleZeroCrossings / cutoff
+	b.first = int(math.Ceil(-halfWidth))
+	b.taps = int(math.Floor(1+halfWidth)) - b.first + 1
+
+	b.kernels = make([]float64, b.phases*b.taps)
+	for p := range b.phases {
+		frac := float64(p) / float64(b.phases)
+		kernel := b.kernels[p*b.taps : (p+1)*b.taps]
+		var sum float64
+		for k := range kernel {
+			x := (float64(b.first+k) - frac) * cutoff
+			kernel[k] = sinc(x) * hann(x/resampleZeroCrossings)
+			sum += kernel[k]
+		}
+		for k := range kernel {
+			kernel[k] /= sum
+		}
+	}
+	return b
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// hann is the Hann window over [-1, 1]
+func hann(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return 0.5 + 0.5*math.Cos(math.Pi*x)
+}
@@ -0,0 +1,90 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResampleLength(t *testing.T) {
+	tests := []struct {
+		n, from, to int
+		want        int
+	}{
+		{48000, 48000, 16000, 16000},
+		{44100, 44100, 16000, 16000},
+		{8000, 8000, 16000, 16000},
+		{1000, 48000, 16000, 333},
+		{1001, 22050, 16000, 726},
+		{1, 8000, 16000, 2},
+		{0, 8000, 16000, 0},
+		{100, 16000, 16000, 100},
+	}
+	for _, tt := range tests {
+		got := Resample(make([]float32, tt.n), tt.from, tt.to)
+		if len(got) != tt.want {
+			t.Errorf("Resample(%d samples, %d, %d) has %d samples, want %d", tt.n, tt.from, tt.to, len(got), tt.want)
+		}
+	}
+}
+
+func TestResampleDCGain(t *testing.T) {
+	for _, rates := range [][2]int{{48000, 16000}, {44100, 16000}, {8000, 16000}, {22050, 16000}} {
+		in := make([]float32, rates[0]/10)
+		for i := range in {
+			in[i] = 0.5
+		}
+		// Weights are normalized, so a constant passes unchanged up to the
+		// edges
+		for i, v := range Resample(in, rates[0], rates[1]) {
+			if math.Abs(float64(v)-0.5) > 1e-4 {
+				t.Errorf("%d to %d Hz: sample %d = %v, want 0.5", rates[0], rates[1], i, v)
+				break
+			}
+		}
+	}
+}
+
+func TestResampleAntiAlias(t *testing.T) {
+	// A 12 kHz tone is above the 8 kHz Nyquist frequency of 16 kHz audio
+	in := make([]float32, 48000)
+	for i := range in {
+		in[i] = float32(math.Sin(2 * math.Pi * 12000 * float64(i) / 48000))
+	}
+	out := Resample(in, 48000, 16000)
+
+	// Skip the edges, where the filter is cut short
+	var energy float64
+	middle := out[1000 : len(out)-1000]
+	for _, v := range middle {
+		energy += float64(v) * float64(v)
+	}
+	if rms := math.Sqrt(energy / float64(len(middle))); rms > 0.01 {
+		t.Errorf("12 kHz tone resampled to 16 kHz has RMS %v, want under 0.01", rms)
+	}
+}
+
+func TestResampleSameRate(t *testing.T) {
+	in := []float32{0.1, 0.2, 0.3}
+	if out := Resample(in, 16000, 16000); &out[0] != &in[0] {
+		t.Error("Resample at the same rate copied the samples")
+	}
+}
+
+func TestResamplePassband(t *testing.T) {
+	// A 1 kHz tone is well inside both passbands, so each output sample
+	// should land on the tone at its own time
+	for _, rates := range [][2]int{{48000, 16000}, {44100, 16000}, {8000, 16000}, {22050, 16000}, {96000, 16000}} {
+		in := make([]float32, rates[0])
+		for i := range in {
+			in[i] = float32(math.Sin(2 * math.Pi * 1000 * float64(i) / float64(rates[0])))
+		}
+		out := Resample(in, rates[0], rates[1])
+		for i := 1000; i < len(out)-1000; i++ {
+			want := math.Sin(2 * math.Pi * 1000 * float64(i) / float64(rates[1]))
+			if math.Abs(float64(out[i])-want) > 0.01 {
+				t.Errorf("%d to %d Hz: sample %d = %v, want %v", rates[0], rates[1], i, out[i], want)
+				break
+			}
+		}
+	}
+}
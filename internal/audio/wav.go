@@ -1,9 +1,13 @@
 package audio
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 )
 
@@ -13,111 +17,386 @@ type Wave struct {
 	SampleRate int
 }
 
-// ReadWave reads a WAV file and returns the audio data
+// Duration returns the length of the audio in seconds
+func (w *Wave) Duration() float64 {
+	if w.SampleRate == 0 {
+		return 0
+	}
+	return float64(len(w.Samples)) / float64(w.SampleRate)
+}
+
+// DownmixChannels selects the average of all channels in ReadWaveChannel
+const DownmixChannels = -1
+
+// WAV format tags
+const (
+	formatPCM        = 0x0001
+	formatFloat      = 0x0003
+	formatExtensible = 0xFFFE
+)
+
+// extensibleGUIDSuffix follows the format tag in the SubFormat GUID of
+// WAVE_FORMAT_EXTENSIBLE files (KSDATAFORMAT_SUBTYPE_*)
+var extensibleGUIDSuffix = []byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71}
+
+// maxChannels is the most channels a WAV file may declare; a header is
+// untrusted input that sizes buffers
+const maxChannels = 64
+
+// readBufferSize is how many bytes of sample data are decoded at a time
+const readBufferSize = 256 << 10
+
+// maxPrealloc caps the samples allocated up front from the header's data
+// size, which may be larger than the file
+const maxPrealloc = 1 << 24
+
+// unknownSize marks RIFF and data sizes that are given by the ds64 chunk
+// (RF64) or not known at all (streamed WAVs)
+const unknownSize = 0xFFFFFFFF
+
+// WaveFormat describes the samples of a WAV file
+type WaveFormat struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int  // container size: 8, 16, 24, 32 or 64
+	Float         bool // IEEE float rather than integer PCM
+
+	// DataSize is the length of the sample data in bytes, or -1 if the
+	// file does not say and the data runs to the end of the file
+	DataSize int64
+}
+
+// BlockAlign returns the size of one frame (a sample of every channel)
+func (f WaveFormat) BlockAlign() int {
+	return f.Channels * f.BitsPerSample / 8
+}
+
+// Frames returns the number of frames, or -1 if DataSize is unknown
+func (f WaveFormat) Frames() int64 {
+	if f.DataSize < 0 {
+		return -1
+	}
+	return f.DataSize / int64(f.BlockAlign())
+}
+
+// Duration returns the length of the audio in seconds, or -1 if DataSize is
+// unknown
+func (f WaveFormat) Duration() float64 {
+	frames := f.Frames()
+	if frames < 0 {
+		return -1
+	}
+	return float64(frames) / float64(f.SampleRate)
+}
+
+// ReadWave reads a WAV file and returns the audio data, averaging all
+// channels to mono
 // This is a pure Go implementation to avoid C memory leaks
 func ReadWave(filename string) (*Wave, error) {
+	return ReadWaveChannel(filename, DownmixChannels)
+}
+
+// ReadWaveChannel reads a WAV file and returns the samples of one channel
+// (0 is the first), or of all channels averaged with DownmixChannels
+func ReadWaveChannel(filename string, channel int) (*Wave, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
+	format, err := ReadWaveHeader(file)
+	if err != nil {
+		return nil, err
+	}
+	if channel >= format.Channels || channel < DownmixChannels {
+		return nil, fmt.Errorf("channel %d out of range (file has %d)", channel, format.Channels)
+	}
+
+	return readSamples(file, format, channel)
+}
+
+// ReadWaveHeader parses the RIFF or RF64 header of a WAV file up to the
+// start of the sample data, where it leaves r
+func ReadWaveHeader(r io.ReadSeeker) (WaveFormat, error) {
+	var format WaveFormat
+
 	// Read RIFF header
 	var riffHeader [12]byte
-	if _, err := io.ReadFull(file, riffHeader[:]); err != nil {
-		return nil, fmt.Errorf("failed to read RIFF header: %w", err)
+	if _, err := io.ReadFull(r, riffHeader[:]); err != nil {
+		return format, fmt.Errorf("failed to read RIFF header: %w", err)
 	}
 
-	// Verify RIFF header
-	if string(riffHeader[0:4]) != "RIFF" {
-		return nil, fmt.Errorf("not a valid WAV file (no RIFF header), got: %s", string(riffHeader[0:4]))
+	// Verify RIFF header; RF64 is RIFF with 64-bit sizes in a ds64 chunk
+	rf64 := string(riffHeader[0:4]) == "RF64"
+	if !rf64 && string(riffHeader[0:4]) != "RIFF" {
+		return format, fmt.Errorf("not a valid WAV file (no RIFF header), got: %s", string(riffHeader[0:4]))
 	}
 
 	// Verify WAVE format
 	if string(riffHeader[8:12]) != "WAVE" {
-		return nil, fmt.Errorf("not a valid WAV file (no WAVE format), got: %s", string(riffHeader[8:12]))
+		return format, fmt.Errorf("not a valid WAV file (no WAVE format), got: %s", string(riffHeader[8:12]))
 	}
 
 	var (
-		sampleRate    int
-		numChannels   int
-		bitsPerSample int
-		dataSize      uint32
+		haveFormat bool
+		ds64Size   int64 = -1
 	)
 
 	// Parse chunks
 	for {
 		// Read chunk header
 		var chunkHeader [8]byte
-		_, err := io.ReadFull(file, chunkHeader[:])
+		_, err := io.ReadFull(r, chunkHeader[:])
 		if err == io.EOF {
-			return nil, fmt.Errorf("no data chunk found in WAV file")
+			return format, fmt.Errorf("no data chunk found in WAV file")
 		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to read chunk header: %w", err)
+			return format, fmt.Errorf("failed to read chunk header: %w", err)
 		}
 
 		chunkID := string(chunkHeader[0:4])
-		chunkSize := binary.LittleEndian.Uint32(chunkHeader[4:8])
+		chunkSize := int64(binary.LittleEndian.Uint32(chunkHeader[4:8]))
 
 		switch chunkID {
-		case "fmt ":
-			// Read fmt chunk
-			fmtData := make([]byte, chunkSize)
-			if _, err := io.ReadFull(file, fmtData); err != nil {
-				return nil, fmt.Errorf("failed to read fmt chunk: %w", err)
+		case "ds64":
+			if chunkSize < 24 {
+				return format, fmt.Errorf("ds64 chunk too short: %d bytes", chunkSize)
 			}
-
-			// Parse fmt chunk
-			audioFormat := binary.LittleEndian.Uint16(fmtData[0:2])
-			if audioFormat != 1 {
-				return nil, fmt.Errorf("unsupported audio format: %d (only PCM supported)", audioFormat)
+			ds64, err := readChunk(r, chunkID, chunkSize)
+			if err != nil {
+				return format, err
 			}
+			// RIFF size (0-8), data size (8-16), sample count (16-24), table
+			ds64Size = int64(binary.LittleEndian.Uint64(ds64[8:16]))
 
-			numChannels = int(binary.LittleEndian.Uint16(fmtData[2:4]))
-			sampleRate = int(binary.LittleEndian.Uint32(fmtData[4:8]))
-			// Skip byte rate (8-12) and block align (12-14)
-			bitsPerSample = int(binary.LittleEndian.Uint16(fmtData[14:16]))
-
-			if numChannels != 1 {
-				return nil, fmt.Errorf("unsupported number of channels: %d (only mono supported)", numChannels)
+		case "fmt ":
+			fmtData, err := readChunk(r, chunkID, chunkSize)
+			if err != nil {
+				return format, err
 			}
-			if bitsPerSample != 16 {
-				return nil, fmt.Errorf("unsupported bits per sample: %d (only 16-bit supported)", bitsPerSample)
+			if format, err = parseFormat(fmtData); err != nil {
+				return format, err
 			}
+			haveFormat = true
 
 		case "data":
-			dataSize = chunkSize
-			// Read the audio data now
-			return readSamples(file, sampleRate, dataSize)
+			if !haveFormat {
+				return format, fmt.Errorf("data chunk before fmt chunk")
+			}
+			switch {
+			case rf64 && chunkSize == unknownSize && ds64Size >= 0:
+				format.DataSize = ds64Size
+			case chunkSize == unknownSize:
+				// Written by a stream that could not seek back to the header
+				format.DataSize = -1
+			default:
+				format.DataSize = chunkSize
+			}
+			return format, nil
 
 		default:
 			// Skip unknown chunks (LIST, INFO, etc.)
-			if _, err := file.Seek(int64(chunkSize), 1); err != nil {
-				return nil, fmt.Errorf("failed to skip chunk %s: %w", chunkID, err)
+			if err := skipChunk(r, chunkID, chunkSize); err != nil {
+				return format, err
 			}
 		}
 	}
 }
 
-func readSamples(file *os.File, sampleRate int, dataSize uint32) (*Wave, error) {
-	// Read all samples
-	data := make([]byte, dataSize)
-	if _, err := io.ReadFull(file, data); err != nil {
-		return nil, fmt.Errorf("failed to read audio data: %w", err)
+// readChunk reads a chunk body and its pad byte
+func readChunk(r io.ReadSeeker, id string, size int64) ([]byte, error) {
+	if size > 1<<20 {
+		return nil, fmt.Errorf("%s chunk too large: %d bytes", id, size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("failed to read %s chunk: %w", id, err)
+	}
+	if size%2 == 1 {
+		if _, err := r.Seek(1, io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("failed to skip %s chunk padding: %w", id, err)
+		}
 	}
+	return data, nil
+}
 
-	// Convert int16 samples to float32
-	numSamples := int(dataSize) / 2
-	samples := make([]float32, numSamples)
+// skipChunk skips a chunk body; chunks are word aligned, so odd sizes are
+// followed by a pad byte
+func skipChunk(r io.ReadSeeker, id string, size int64) error {
+	if _, err := r.Seek(size+size%2, io.SeekCurrent); err != nil {
+		return fmt.Errorf("failed to skip chunk %s: %w", id, err)
+	}
+	return nil
+}
 
-	for i := 0; i < numSamples; i++ {
-		sample := int16(binary.LittleEndian.Uint16(data[i*2 : (i+1)*2]))
-		samples[i] = float32(sample) / 32768.0
+// parseFormat parses a fmt chunk (WAVEFORMAT, WAVEFORMATEX or
+// WAVEFORMATEXTENSIBLE)
+func parseFormat(fmtData []byte) (WaveFormat, error) {
+	var format WaveFormat
+	if len(fmtData) < 16 {
+		return format, fmt.Errorf("fmt chunk too short: %d bytes", len(fmtData))
+	}
+
+	audioFormat := binary.LittleEndian.Uint16(fmtData[0:2])
+	format.Channels = int(binary.LittleEndian.Uint16(fmtData[2:4]))
+	format.SampleRate = int(binary.LittleEndian.Uint32(fmtData[4:8]))
+	// Skip byte rate (8-12)
+	blockAlign := int(binary.LittleEndian.Uint16(fmtData[12:14]))
+	bitsPerSample := int(binary.LittleEndian.Uint16(fmtData[14:16]))
+
+	if audioFormat == formatExtensible {
+		// cbSize (16-18), valid bits (18-20), channel mask (20-24), SubFormat
+		if len(fmtData) < 40 {
+			return format, fmt.Errorf("WAVE_FORMAT_EXTENSIBLE fmt chunk too short: %d bytes", len(fmtData))
+		}
+		if !bytes.Equal(fmtData[26:40], extensibleGUIDSuffix) {
+			return format, fmt.Errorf("unsupported WAVE_FORMAT_EXTENSIBLE sub-format")
+		}
+		audioFormat = binary.LittleEndian.Uint16(fmtData[24:26])
+	}
+
+	if format.Channels < 1 || format.Channels > maxChannels {
+		return format, fmt.Errorf("invalid number of channels: %d (supported: 1 to %d)", format.Channels, maxChannels)
+	}
+	if format.SampleRate < 1 {
+		return format, fmt.Errorf("invalid sample rate: %d", format.SampleRate)
+	}
+
+	// Samples narrower than their container (20-bit in 24, say) are left
+	// justified, so they decode as the container size
+	format.BitsPerSample = (bitsPerSample + 7) / 8 * 8
+	if blockAlign > 0 && blockAlign%format.Channels == 0 && blockAlign/format.Channels*8 > format.BitsPerSample {
+		format.BitsPerSample = blockAlign / format.Channels * 8
+	}
+
+	switch audioFormat {
+	case formatPCM:
+		switch format.BitsPerSample {
+		case 8, 16, 24, 32:
+		default:
+			return format, fmt.Errorf("unsupported bits per sample: %d (PCM supports 8, 16, 24 and 32)", bitsPerSample)
+		}
+	case formatFloat:
+		switch format.BitsPerSample {
+		case 32, 64:
+		default:
+			return format, fmt.Errorf("unsupported bits per sample: %d (float supports 32 and 64)", bitsPerSample)
+		}
+		format.Float = true
+	default:
+		return format, fmt.Errorf("unsupported audio format: %d (only PCM and IEEE float supported)", audioFormat)
+	}
+
+	return format, nil
+}
+
+// readSamples decodes the sample data at r to float32 in [-1, 1), keeping
+// one channel or averaging all of them
+func readSamples(r io.Reader, format WaveFormat, channel int) (*Wave, error) {
+	blockAlign := format.BlockAlign()
+	frames := format.Frames()
+
+	var samples []float32
+	if frames >= 0 {
+		r = io.LimitReader(r, format.DataSize)
+		samples = make([]float32, 0, min(frames, maxPrealloc))
+	}
+
+	// Decode whole frames a block at a time to bound memory beyond the samples
+	buf := make([]byte, max(readBufferSize/blockAlign, 1)*blockAlign)
+	br := bufio.NewReader(r)
+	for {
+		n, err := io.ReadFull(br, buf)
+		// A truncated last frame is dropped
+		samples = appendFrames(samples, buf[:n-n%blockAlign], format, channel)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audio data: %w", err)
+		}
 	}
 
 	return &Wave{
 		Samples:    samples,
-		SampleRate: sampleRate,
+		SampleRate: format.SampleRate,
 	}, nil
 }
+
+// appendFrames decodes whole frames from data and appends them to samples
+func appendFrames(samples []float32, data []byte, format WaveFormat, channel int) []float32 {
+	width := format.BitsPerSample / 8
+	blockAlign := format.BlockAlign()
+	scale := 1 / float32(format.Channels)
+
+	for frame := 0; frame+blockAlign <= len(data); frame += blockAlign {
+		if channel != DownmixChannels {
+			offset := frame + channel*width
+			samples = append(samples, decodeSample(data[offset:offset+width], format.Float))
+			continue
+		}
+		var sum float32
+		for c := 0; c < format.Channels; c++ {
+			offset := frame + c*width
+			sum += decodeSample(data[offset:offset+width], format.Float)
+		}
+		samples = append(samples, sum*scale)
+	}
+	return samples
+}
+
+// decodeSample converts one little-endian sample to float32 in [-1, 1)
+func decodeSample(b []byte, float bool) float32 {
+	if float {
+		if len(b) == 8 {
+			return float32(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	}
+
+	switch len(b) {
+	case 1:
+		// 8-bit PCM is unsigned
+		return float32(int(b[0])-128) / 128.0
+	case 2:
+		return float32(int16(binary.LittleEndian.Uint16(b))) / 32768.0
+	case 3:
+		v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+		return float32(v) / 8388608.0
+	default:
+		return float32(float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648.0)
+	}
+}
+
+// WriteWave writes mono samples as a 16-bit PCM WAV file, clipping them to
+// [-1, 1]
+func WriteWave(filename string, wave *Wave) error {
+	dataSize := len(wave.Samples) * 2
+	buf := make([]byte, 44+dataSize)
+
+	copy(buf[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(36+dataSize))
+	copy(buf[8:12], "WAVE")
+	copy(buf[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(buf[16:20], 16)
+	binary.LittleEndian.PutUint16(buf[20:22], formatPCM)
+	binary.LittleEndian.PutUint16(buf[22:24], 1)
+	binary.LittleEndian.PutUint32(buf[24:28], uint32(wave.SampleRate))
+	binary.LittleEndian.PutUint32(buf[28:32], uint32(wave.SampleRate*2))
+	binary.LittleEndian.PutUint16(buf[32:34], 2)
+	binary.LittleEndian.PutUint16(buf[34:36], 16)
+	copy(buf[36:40], "data")
+	binary.LittleEndian.PutUint32(buf[40:44], uint32(dataSize))
+
+	for i, s := range wave.Samples {
+		v := math.Round(float64(s) * 32768)
+		v = math.Max(-32768, math.Min(32767, v))
+		binary.LittleEndian.PutUint16(buf[44+i*2:], uint16(int16(v)))
+	}
+
+	if err := os.WriteFile(filename, buf, 0o644); err != nil {
+		return fmt.Errorf("failed to write WAV file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// chunk encodes a RIFF chunk, padding odd bodies to a word boundary
+func chunk(id string, body []byte) []byte {
+	return sizedChunk(id, uint32(len(body)), body)
+}
+
+// sizedChunk encodes a chunk header with the given size, which need not be
+// the length of body
+func sizedChunk(id string, size uint32, body []byte) []byte {
+	b := append([]byte(id), binary.LittleEndian.AppendUint32(nil, size)...)
+	b = append(b, body...)
+	if len(body)%2 == 1 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// fmtBody encodes a 16-byte WAVEFORMAT
+func fmtBody(tag, channels uint16, rate uint32, bits uint16) []byte {
+	blockAlign := channels * ((bits + 7) / 8)
+	b := binary.LittleEndian.AppendUint16(nil, tag)
+	b = binary.LittleEndian.AppendUint16(b, channels)
+	b = binary.LittleEndian.AppendUint32(b, rate)
+	b = binary.LittleEndian.AppendUint32(b, rate*uint32(blockAlign))
+	b = binary.LittleEndian.AppendUint16(b, blockAlign)
+	return binary.LittleEndian.AppendUint16(b, bits)
+}
+
+// extensibleBody encodes a WAVEFORMATEXTENSIBLE with the given sub-format
+func extensibleBody(subFormat, channels uint16, rate uint32, bits uint16) []byte {
+	b := fmtBody(formatExtensible, channels, rate, bits)
+	b = binary.LittleEndian.AppendUint16(b, 22)   // cbSize
+	b = binary.LittleEndian.AppendUint16(b, bits) // valid bits
+	b = binary.LittleEndian.AppendUint32(b, 0)    // channel mask
+	b = binary.LittleEndian.AppendUint16(b, subFormat)
+	return append(b, extensibleGUIDSuffix...)
+}
+
+// wav wraps chunks in a RIFF (or RF64) header
+func wav(magic string, chunks ...[]byte) []byte {
+	body := bytes.Join(chunks, nil)
+	b := append([]byte(magic), binary.LittleEndian.AppendUint32(nil, uint32(4+len(body)))...)
+	return append(append(b, "WAVE"...), body...)
+}
+
+func TestReadWaveHeader(t *testing.T) {
+	pcm16 := chunk("fmt ", fmtBody(formatPCM, 1, 16000, 16))
+	ds64 := binary.LittleEndian.AppendUint64(nil, 0)
+	ds64 = binary.LittleEndian.AppendUint64(ds64, 1<<33)
+	ds64 = binary.LittleEndian.AppendUint64(ds64, 0)
+	ds64 = append(ds64, 0, 0, 0, 0) // empty table
+
+	tests := []struct {
+		name string
+		data []byte
+		want WaveFormat
+		err  bool
+	}{
+		{
+			name: "pcm",
+			data: wav("RIFF", pcm16, chunk("data", make([]byte, 8))),
+			want: WaveFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 16, DataSize: 8},
+		},
+		{
+			name: "odd chunk padding",
+			data: wav("RIFF", chunk("LIST", []byte("odd")), pcm16, chunk("junk", []byte{1}), chunk("data", make([]byte, 4))),
+			want: WaveFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 16, DataSize: 4},
+		},
+		{
+			name: "odd fmt chunk",
+			data: wav("RIFF", chunk("fmt ", append(fmtBody(formatPCM, 2, 8000, 8), 0)), chunk("data", make([]byte, 2))),
+			want: WaveFormat{SampleRate: 8000, Channels: 2, BitsPerSample: 8, DataSize: 2},
+		},
+		{
+			name: "extensible 24-bit",
+			data: wav("RIFF", chunk("fmt ", extensibleBody(formatPCM, 2, 48000, 24)), chunk("data", make([]byte, 12))),
+			want: WaveFormat{SampleRate: 48000, Channels: 2, BitsPerSample: 24, DataSize: 12},
+		},
+		{
+			name: "extensible float",
+			data: wav("RIFF", chunk("fmt ", extensibleBody(formatFloat, 1, 44100, 32)), chunk("data", make([]byte, 4))),
+			want: WaveFormat{SampleRate: 44100, Channels: 1, BitsPerSample: 32, Float: true, DataSize: 4},
+		},
+		{
+			name: "20-bit in 24-bit container",
+			data: wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 1, 16000, 20)), chunk("data", make([]byte, 6))),
+			want: WaveFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 24, DataSize: 6},
+		},
+		{
+			name: "float64",
+			data: wav("RIFF", chunk("fmt ", fmtBody(formatFloat, 1, 16000, 64)), chunk("data", make([]byte, 8))),
+			want: WaveFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 64, Float: true, DataSize: 8},
+		},
+		{
+			name: "rf64",
+			data: wav("RF64", chunk("ds64", ds64), pcm16, sizedChunk("data", unknownSize, nil)),
+			want: WaveFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 16, DataSize: 1 << 33},
+		},
+		{
+			name: "streamed",
+			data: wav("RIFF", pcm16, sizedChunk("data", unknownSize, nil)),
+			want: WaveFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 16, DataSize: -1},
+		},
+		{
+			name: "empty data chunk",
+			data: wav("RIFF", pcm16, chunk("data", nil)),
+			want: WaveFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 16, DataSize: 0},
+		},
+		{name: "not riff", data: append([]byte("RIFX"), wav("RIFF", pcm16)[4:]...), err: true},
+		{name: "not wave", data: append([]byte("RIFF\x04\x00\x00\x00AVI "), pcm16...), err: true},
+		{name: "no data chunk", data: wav("RIFF", pcm16), err: true},
+		{name: "data before fmt", data: wav("RIFF", chunk("data", nil), pcm16), err: true},
+		{name: "short fmt", data: wav("RIFF", chunk("fmt ", make([]byte, 14)), chunk("data", nil)), err: true},
+		{name: "short ds64", data: wav("RF64", chunk("ds64", make([]byte, 16)), pcm16, chunk("data", nil)), err: true},
+		{name: "no channels", data: wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 0, 16000, 16)), chunk("data", nil)), err: true},
+		{name: "too many channels", data: wav("RIFF", chunk("fmt ", fmtBody(formatPCM, maxChannels+1, 16000, 16)), chunk("data", nil)), err: true},
+		{name: "no sample rate", data: wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 1, 0, 16)), chunk("data", nil)), err: true},
+		{name: "adpcm", data: wav("RIFF", chunk("fmt ", fmtBody(0x0002, 1, 16000, 4)), chunk("data", nil)), err: true},
+		{name: "16-bit float", data: wav("RIFF", chunk("fmt ", fmtBody(formatFloat, 1, 16000, 16)), chunk("data", nil)), err: true},
+		{name: "40-bit pcm", data: wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 1, 16000, 40)), chunk("data", nil)), err: true},
+		{name: "unknown extensible sub-format", data: wav("RIFF", chunk("fmt ", append(extensibleBody(formatPCM, 1, 16000, 16)[:39], 0xFF)), chunk("data", nil)), err: true},
+		{name: "truncated header", data: []byte("RIFF\x00\x00"), err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadWaveHeader(bytes.NewReader(tt.data))
+			if (err != nil) != tt.err {
+				t.Fatalf("ReadWaveHeader() error = %v, want error %v", err, tt.err)
+			}
+			if !tt.err && got != tt.want {
+				t.Errorf("ReadWaveHeader() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// samples encodes values with enc, one after another
+func samples[T any](enc func([]byte, T) []byte, values ...T) []byte {
+	var b []byte
+	for _, v := range values {
+		b = enc(b, v)
+	}
+	return b
+}
+
+func int16LE(b []byte, v int16) []byte {
+	return binary.LittleEndian.AppendUint16(b, uint16(v))
+}
+
+func int24LE(b []byte, v int32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16))
+}
+
+func int32LE(b []byte, v int32) []byte {
+	return binary.LittleEndian.AppendUint32(b, uint32(v))
+}
+
+func float32LE(b []byte, v float32) []byte {
+	return binary.LittleEndian.AppendUint32(b, math.Float32bits(v))
+}
+
+func float64LE(b []byte, v float64) []byte {
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+}
+
+func TestReadWaveChannel(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		channel int
+		want    []float32
+	}{
+		{
+			name:    "8-bit unsigned",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 1, 8000, 8)), chunk("data", []byte{0, 128, 192})),
+			channel: DownmixChannels,
+			want:    []float32{-1, 0, 0.5},
+		},
+		{
+			name:    "16-bit",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 1, 16000, 16)), chunk("data", samples(int16LE, -32768, 0, 16384))),
+			channel: DownmixChannels,
+			want:    []float32{-1, 0, 0.5},
+		},
+		{
+			name:    "24-bit",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 1, 16000, 24)), chunk("data", samples(int24LE, -8388608, 0, 4194304, -4194304))),
+			channel: DownmixChannels,
+			want:    []float32{-1, 0, 0.5, -0.5},
+		},
+		{
+			name:    "32-bit",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 1, 16000, 32)), chunk("data", samples(int32LE, math.MinInt32, 0, 1<<30))),
+			channel: DownmixChannels,
+			want:    []float32{-1, 0, 0.5},
+		},
+		{
+			name:    "float32",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatFloat, 1, 16000, 32)), chunk("data", samples(float32LE, -0.25, 0.75))),
+			channel: DownmixChannels,
+			want:    []float32{-0.25, 0.75},
+		},
+		{
+			name:    "float64",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatFloat, 1, 16000, 64)), chunk("data", samples(float64LE, -0.25, 0.75))),
+			channel: DownmixChannels,
+			want:    []float32{-0.25, 0.75},
+		},
+		{
+			name:    "stereo downmix",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 2, 16000, 16)), chunk("data", samples(int16LE, 16384, 0, -16384, -16384))),
+			channel: DownmixChannels,
+			want:    []float32{0.25, -0.5},
+		},
+		{
+			name:    "right channel",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 2, 16000, 16)), chunk("data", samples(int16LE, 16384, 0, -16384, 8192))),
+			channel: 1,
+			want:    []float32{0, 0.25},
+		},
+		{
+			name:    "data after padded chunk",
+			data:    wav("RIFF", chunk("LIST", []byte("odd")), chunk("fmt ", fmtBody(formatPCM, 1, 16000, 16)), chunk("data", samples(int16LE, 16384))),
+			channel: DownmixChannels,
+			want:    []float32{0.5},
+		},
+		{
+			name:    "truncated data",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 2, 16000, 16)), sizedChunk("data", 100, append(samples(int16LE, 16384, 16384), 1, 2))),
+			channel: DownmixChannels,
+			want:    []float32{0.5},
+		},
+		{
+			name:    "trailing chunk after data",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 1, 16000, 16)), chunk("data", samples(int16LE, 16384)), chunk("LIST", make([]byte, 6))),
+			channel: DownmixChannels,
+			want:    []float32{0.5},
+		},
+		{
+			name:    "streamed",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 1, 16000, 16)), sizedChunk("data", unknownSize, samples(int16LE, 16384, -16384))),
+			channel: DownmixChannels,
+			want:    []float32{0.5, -0.5},
+		},
+		{
+			name:    "empty data chunk",
+			data:    wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 1, 16000, 16)), chunk("data", nil), chunk("LIST", make([]byte, 6))),
+			channel: DownmixChannels,
+			want:    []float32{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "in.wav")
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			wave, err := ReadWaveChannel(path, tt.channel)
+			if err != nil {
+				t.Fatalf("ReadWaveChannel() error = %v", err)
+			}
+			if len(wave.Samples) != len(tt.want) {
+				t.Fatalf("got %d samples %v, want %v", len(wave.Samples), wave.Samples, tt.want)
+			}
+			for i := range tt.want {
+				if math.Abs(float64(wave.Samples[i]-tt.want[i])) > 1e-6 {
+					t.Errorf("sample %d = %v, want %v", i, wave.Samples[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadWaveChannelOutOfRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "in.wav")
+	data := wav("RIFF", chunk("fmt ", fmtBody(formatPCM, 2, 16000, 16)), chunk("data", make([]byte, 4)))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, channel := range []int{2, -2} {
+		if _, err := ReadWaveChannel(path, channel); err == nil {
+			t.Errorf("ReadWaveChannel(%d) succeeded on a stereo file", channel)
+		}
+	}
+}
+
+func TestWriteWave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	in := &Wave{Samples: []float32{0, 0.5, -0.5, 2, -2}, SampleRate: 22050}
+	if err := WriteWave(path, in); err != nil {
+		t.Fatal(err)
+	}
+	out, err := ReadWave(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.SampleRate != in.SampleRate {
+		t.Errorf("sample rate = %d, want %d", out.SampleRate, in.SampleRate)
+	}
+	// Out-of-range samples are clipped
+	want := []float32{0, 0.5, -0.5, 32767.0 / 32768, -1}
+	if len(out.Samples) != len(want) {
+		t.Fatalf("got %d samples, want %d", len(out.Samples), len(want))
+	}
+	for i := range want {
+		if out.Samples[i] != want[i] {
+			t.Errorf("sample %d = %v, want %v", i, out.Samples[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds settings loaded from the config file and environment.
+// Precedence is flag > env > file > default; defaults and flags are applied
+// by the CLI, which maps these onto the recognizer, server and output
+// options.
+type Config struct {
+	Model string `toml:"model"` // CHOUGH_MODEL
+	URL   string `toml:"url"`   // CHOUGH_URL
+
+	PunctuationModel string `toml:"punctuation_model"` // CHOUGH_PUNCT_MODEL
+	LanguageIDModel  string `toml:"language_id_model"` // CHOUGH_LID_MODEL
+	VADModel         string `toml:"vad_model"`         // CHOUGH_VAD_MODEL
+
+	Transcribe TranscribeConfig `toml:"transcribe"`
+	ASR        ASRConfig        `toml:"asr"`
+	Subtitles  SubtitlesConfig  `toml:"subtitles"`
+	Server     ServerConfig     `toml:"server"`
+
+	// Path is the config file that was loaded, empty if none
+	Path string `toml:"-"`
+}
+
+// TranscribeConfig holds CLI transcription settings
+type TranscribeConfig struct {
+	ChunkSize      int     `toml:"chunk_size"`
+	Format         string  `toml:"format"`
+	Output         string  `toml:"output"`
+	Remote         bool    `toml:"remote"`
+	MinConfidence  float64 `toml:"min_confidence"`
+	LowConfidence  string  `toml:"low_confidence"` // mark, drop
+	Punctuate      bool    `toml:"punctuate"`
+	ITN            bool    `toml:"itn"`
+	Task           string  `toml:"task"`     // transcribe, translate (Whisper)
+	Language       string  `toml:"language"` // ISO 639-1, skips identification
+	DetectLanguage bool    `toml:"detect_language"`
+	ParagraphGap   float64 `toml:"paragraph_gap"` // seconds
+	Timestamps     bool    `toml:"timestamps"`
+	FrameRate      float64 `toml:"frame_rate"`   // edl and markers timecode
+	EmbedSubs      string  `toml:"embed_subs"`   // video to write with subtitles
+	BurnIn         bool    `toml:"burn_in"`      // render embed_subs into the video
+	SubLanguage    string  `toml:"sub_language"` // ISO 639-2, for embed_subs; empty uses the transcript's
+	AudioStream    int     `toml:"audio_stream"` // 0 is the first
+	Channel        string  `toml:"channel"`      // left, right or a number from 1
+	SplitChannels  bool    `toml:"split_channels"`
+	Preprocess     string  `toml:"preprocess"` // none, normalize, phone, noisy
+	HighPass       float64 `toml:"highpass"`   // Hz, replaces the preset's cutoff
+	Denoise        bool    `toml:"denoise"`
+	Loudnorm       bool    `toml:"loudnorm"`
+	VAD            bool    `toml:"vad"`          // transcribe only detected speech
+	MaxDownload    int     `toml:"max_download"` // MB, for http(s) input
+}
+
+// ASRConfig holds recognizer settings
+type ASRConfig struct {
+	Threads        int     `toml:"threads"`
+	Provider       string  `toml:"provider"`
+	DecodingMethod string  `toml:"decoding_method"`
+	MaxActivePaths int     `toml:"max_active_paths"`
+	BlankPenalty   float64 `toml:"blank_penalty"`
+	HotwordsFile   string  `toml:"hotwords_file"`
+	HotwordsScore  float64 `toml:"hotwords_score"`
+}
+
+// SubtitlesConfig holds subtitle cue rules
+type SubtitlesConfig struct {
+	MaxCueDuration    float64 `toml:"max_cue_duration"` // seconds
+	MinCueDuration    float64 `toml:"min_cue_duration"` // seconds
+	MaxLineChars      int     `toml:"max_line_chars"`   // 0 disables wrapping
+	MaxLines          int     `toml:"max_lines"`
+	MaxCPS            float64 `toml:"max_cps"`        // 0 disables
+	MinCueGap         float64 `toml:"min_cue_gap"`    // seconds
+	SplitOnComma      bool    `toml:"split_on_comma"` // also split after , ; :
+	SplitOnPause      float64 `toml:"split_on_pause"` // seconds, 0 disables
+	CuePadding        float64 `toml:"cue_padding"`    // seconds
+	ExtendIntoSilence bool    `toml:"extend_into_silence"`
+}
+
+// ServerConfig holds server settings
+type ServerConfig struct {
+	Host           string `toml:"host"`
+	Port           int    `toml:"port"`
+	Workers        int    `toml:"workers"`
+	MaxUpload      int    `toml:"max_upload"`      // MB
+	Punctuate      bool   `toml:"punctuate"`       // load the punctuation model
+	DetectLanguage bool   `toml:"detect_language"` // load the language identification model
+	VAD            bool   `toml:"vad"`             // load the VAD model
+
+	// Downloads of the url request field
+	URLSchemes      []string `toml:"url_schemes"`       // http, https
+	URLAllow        []string `toml:"url_allow"`         // hosts or networks, empty allows any
+	URLDeny         []string `toml:"url_deny"`          // hosts or networks
+	URLAllowPrivate bool     `toml:"url_allow_private"` // loopback, private and link-local
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/chough/config.toml
+func DefaultPath() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "chough", "config.toml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".config", "chough", "config.toml")
+	}
+	return filepath.Join(home, ".config", "chough", "config.toml")
+}
+
+// Load overlays defaults with the config file and environment and returns
+// it. If path is empty, CHOUGH_CONFIG or the default path is used and a
+// missing file is not an error.
+func Load(path string, defaults *Config) (*Config, error) {
+	cfg := defaults
+
+	explicit := path != ""
+	if !explicit {
+		if env := os.Getenv("CHOUGH_CONFIG"); env != "" {
+			path = env
+			explicit = true
+		} else {
+			path = DefaultPath()
+		}
+	}
+
+	md, err := toml.DecodeFile(path, cfg)
+	switch {
+	case err == nil:
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, 0, len(undecoded))
+			for _, k := range undecoded {
+				keys = append(keys, k.String())
+			}
+			return nil, fmt.Errorf("config %s: unknown keys: %s", path, strings.Join(keys, ", "))
+		}
+		cfg.Path = path
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+		// No config file, defaults only
+	default:
+		return nil, fmt.Errorf("failed to load config %s: %w", path, err)
+	}
+
+	cfg.applyEnv()
+	return cfg, nil
+}
+
+func (c *Config) applyEnv() {
+	if v := os.Getenv("CHOUGH_MODEL"); v != "" {
+		c.Model = v
+	}
+	if v := os.Getenv("CHOUGH_URL"); v != "" {
+		c.URL = v
+	}
+	if v := os.Getenv("CHOUGH_PUNCT_MODEL"); v != "" {
+		c.PunctuationModel = v
+	}
+	if v := os.Getenv("CHOUGH_LID_MODEL"); v != "" {
+		c.LanguageIDModel = v
+	}
+	if v := os.Getenv("CHOUGH_VAD_MODEL"); v != "" {
+		c.VADModel = v
+	}
+}
+
+// Write writes the config as TOML
+func (c *Config) Write(out io.Writer) error {
+	enc := toml.NewEncoder(out)
+	enc.Indent = ""
+	return enc.Encode(c)
+}
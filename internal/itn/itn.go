@@ -0,0 +1,283 @@
+// Package itn implements inverse text normalization: it rewrites spoken
+// numbers, currency, percentages, ordinals and dates ("twenty three dollars
+// and fifty cents") in written form ("$23.50"). Rules cover English.
+package itn
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// Span is a run of words [First, Last] to be replaced by Text
+type Span struct {
+	First int
+	Last  int
+	Text  string
+}
+
+// part is a word, or a piece of a hyphenated word ("twenty-three")
+type part struct {
+	text   string // lower case, without trailing punctuation
+	word   int
+	first  bool // first part of its word
+	last   bool // last part of its word
+	closed bool // followed by punctuation; a span cannot continue past it
+}
+
+type parser struct {
+	words []string
+	parts []part
+}
+
+func newParser(words []string) *parser {
+	p := &parser{words: words}
+	for wi, w := range words {
+		clean := strings.ToLower(strings.TrimRightFunc(w, unicode.IsPunct))
+		closed := len(clean) < len(w)
+		pieces := strings.Split(clean, "-")
+		for k, piece := range pieces {
+			last := k == len(pieces)-1
+			p.parts = append(p.parts, part{
+				text:   piece,
+				word:   wi,
+				first:  k == 0,
+				last:   last,
+				closed: last && closed,
+			})
+		}
+	}
+	return p
+}
+
+// continues reports whether a span may extend from part i to part i+1
+func (p *parser) continues(i int) bool {
+	return i+1 < len(p.parts) && !p.parts[i].closed
+}
+
+// Normalize returns the spans of words that have a written form. Words may
+// carry punctuation and casing; trailing punctuation is kept in the span.
+func Normalize(words []string) []Span {
+	p := newParser(words)
+
+	var spans []Span
+	for i := 0; i < len(p.parts); {
+		if !p.parts[i].first {
+			i++
+			continue
+		}
+
+		text, end, ok := p.date(i)
+		if !ok {
+			text, end, ok = p.measure(i)
+		}
+		if !ok || !p.parts[end-1].last {
+			i++
+			continue
+		}
+
+		last := p.parts[end-1].word
+		raw := p.words[last]
+		trailing := raw[len(strings.TrimRightFunc(raw, unicode.IsPunct)):]
+		spans = append(spans, Span{
+			First: p.parts[i].word,
+			Last:  last,
+			Text:  text + trailing,
+		})
+		i = end
+	}
+	return spans
+}
+
+var currencies = map[string]struct{ symbol, subunit string }{
+	"dollar": {"$", "cent"}, "dollars": {"$", "cent"},
+	"euro": {"€", "cent"}, "euros": {"€", "cent"},
+	"pound": {"£", "pence"}, "pounds": {"£", "pence"},
+}
+
+var subunits = map[string]string{
+	"cent": "cent", "cents": "cent",
+	"penny": "pence", "pence": "pence",
+}
+
+var subunitSymbols = map[string]string{"cent": "¢", "pence": "p"}
+
+var largeScales = map[string]bool{"million": true, "billion": true, "trillion": true}
+
+// measure rewrites a number with an optional unit: currency, percent or a
+// large scale word. Plain numbers below ten stay spelled out.
+func (p *parser) measure(i int) (string, int, bool) {
+	n, ok := p.number(i)
+	if !ok {
+		return "", 0, false
+	}
+	end := n.end
+
+	next := func() string {
+		if !p.continues(end - 1) {
+			return ""
+		}
+		return p.parts[end].text
+	}
+
+	if n.ordinal {
+		if n.value < 10 {
+			return "", 0, false
+		}
+		return groupThousands(strconvInt(n.value)) + ordinalSuffix(n.value), end, true
+	}
+
+	// "two point five million"
+	scale := ""
+	if n.frac != "" && largeScales[next()] {
+		scale = " " + next()
+		end++
+	}
+
+	if c, ok := currencies[next()]; ok {
+		end++
+		amount := n.digits(true)
+		if n.frac != "" && len(n.frac) == 1 && scale == "" {
+			amount += "0"
+		}
+
+		// "twenty three dollars and fifty cents"
+		if n.frac == "" && scale == "" && next() == "and" && p.continues(end) {
+			if cents, ok := p.cardinal(end + 1); ok && !cents.ordinal && cents.value < 100 &&
+				p.continues(cents.end-1) && subunits[p.parts[cents.end].text] == c.subunit {
+				amount += "." + twoDigits(cents.value)
+				end = cents.end + 1
+			}
+		}
+		return c.symbol + amount + scale, end, true
+	}
+
+	if unit, ok := subunits[next()]; ok && n.frac == "" && n.year == "" {
+		return n.digits(true) + subunitSymbols[unit], end + 1, true
+	}
+
+	if next() == "percent" {
+		return n.digits(true) + scale + "%", end + 1, true
+	}
+	if next() == "per" && p.continues(end) && p.parts[end+1].text == "cent" {
+		return n.digits(true) + scale + "%", end + 2, true
+	}
+
+	switch {
+	case n.year != "", n.frac != "":
+		return n.digits(false) + scale, end, true
+	case n.value >= 10_000:
+		return n.digits(true), end, true
+	case n.value >= 10:
+		return n.digits(false), end, true
+	}
+	return "", 0, false
+}
+
+var months = map[string]bool{
+	"january": true, "february": true, "march": true, "april": true,
+	"may": true, "june": true, "july": true, "august": true,
+	"september": true, "october": true, "november": true, "december": true,
+}
+
+// date rewrites "March fifth", "March fifth twenty twenty four" and "March
+// twenty twenty four". The month must be capitalized, since "may" and
+// "march" are also common words.
+func (p *parser) date(i int) (string, int, bool) {
+	pt := p.parts[i]
+	if !pt.first || !pt.last || !months[pt.text] || !p.continues(i) {
+		return "", 0, false
+	}
+	month := strings.TrimRightFunc(p.words[pt.word], unicode.IsPunct)
+	if r := []rune(month); !unicode.IsUpper(r[0]) {
+		return "", 0, false
+	}
+
+	if y, end, ok := p.year(i + 1); ok {
+		return month + " " + y, end, true
+	}
+
+	day, ok := p.cardinal(i + 1)
+	if !ok || day.value < 1 || day.value > 31 {
+		return "", 0, false
+	}
+	text := month + " " + strconvInt(day.value)
+	end := day.end
+
+	if p.continues(end - 1) {
+		if y, yEnd, ok := p.year(end); ok {
+			return text + ", " + y, yEnd, true
+		}
+		if c, ok := p.cardinal(end); ok && !c.ordinal && c.value >= 1000 && c.value < 3000 {
+			return text + ", " + strconvInt(c.value), c.end, true
+		}
+	}
+	return text, end, true
+}
+
+// Apply normalizes the chunk's text. Each normalized span becomes a single
+// token that starts at the first replaced token, so word timings are kept.
+// The original text is saved in RawText.
+func Apply(r *types.ChunkResult) {
+	words := types.GroupWords(*r)
+	if len(words) == 0 {
+		return
+	}
+
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.Text
+	}
+	spans := Normalize(texts)
+	if len(spans) == 0 {
+		return
+	}
+
+	hasConfidences := len(r.Confidences) >= len(r.Tokens)
+	hasDurations := len(r.Durations) > 0 && len(r.Durations) >= len(r.Tokens)
+	tokens := make([]string, 0, len(r.Tokens))
+	timestamps := make([]float32, 0, len(r.Timestamps))
+	var confidences, durations []float32
+
+	next := 0
+	for _, s := range spans {
+		first := words[s.First].FirstToken
+		last := words[s.Last].LastToken
+
+		tokens = append(tokens, r.Tokens[next:first]...)
+		timestamps = append(timestamps, r.Timestamps[next:first]...)
+
+		prefix := ""
+		if strings.HasPrefix(r.Tokens[first], " ") {
+			prefix = " "
+		}
+		tokens = append(tokens, prefix+s.Text)
+		timestamps = append(timestamps, r.Timestamps[first])
+
+		if hasConfidences {
+			confidences = append(confidences, r.Confidences[next:first]...)
+			confidences = append(confidences, types.MeanConfidence(r.Confidences[first:last+1]))
+		}
+		if hasDurations {
+			durations = append(durations, r.Durations[next:first]...)
+			durations = append(durations, float32(r.TokenEnd(last))-r.Timestamps[first])
+		}
+		next = last + 1
+	}
+	tokens = append(tokens, r.Tokens[next:]...)
+	timestamps = append(timestamps, r.Timestamps[min(next, len(r.Timestamps)):]...)
+	if hasConfidences {
+		confidences = append(confidences, r.Confidences[next:]...)
+		r.Confidences = confidences
+	}
+	if hasDurations {
+		r.Durations = append(durations, r.Durations[next:]...)
+	}
+
+	r.RawText = r.Text
+	r.Tokens = tokens
+	r.Timestamps = timestamps
+	r.Text = strings.TrimSpace(strings.Join(tokens, ""))
+	r.UpdateWords()
+}
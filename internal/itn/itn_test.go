@@ -0,0 +1,127 @@
+package itn
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Span
+	}{
+		{"it costs twenty three dollars", []Span{{2, 4, "$23"}}},
+		{"twenty three dollars and fifty cents.", []Span{{0, 5, "$23.50."}}},
+		{"two point five million euros", []Span{{0, 4, "€2.5 million"}}},
+		{"one point five pounds", []Span{{0, 3, "£1.50"}}},
+		{"fifty cents", []Span{{0, 1, "50¢"}}},
+		{"ninety nine percent", []Span{{0, 2, "99%"}}},
+		{"five per cent", []Span{{0, 2, "5%"}}},
+		{"three point one four", []Span{{0, 3, "3.14"}}},
+		{"twelve thousand five hundred people", []Span{{0, 3, "12,500"}}},
+		{"one thousand two hundred", []Span{{0, 3, "1200"}}},
+		{"twenty-three apples", []Span{{0, 0, "23"}}},
+		{"the twenty first century", []Span{{1, 2, "21st"}}},
+		{"the third time", nil},
+		{"in nineteen ninety nine", []Span{{1, 3, "1999"}}},
+		{"nineteen oh five", []Span{{0, 2, "1905"}}},
+		{"on March fifth twenty twenty four", []Span{{1, 5, "March 5, 2024"}}},
+		{"March twenty twenty four", []Span{{0, 3, "March 2024"}}},
+		{"March fifth,", []Span{{0, 1, "March 5,"}}},
+		{"may fifth", nil},
+		{"Twenty Three Dollars", []Span{{0, 2, "$23"}}},
+		{"one two three", nil},
+		{"seven dogs", nil},
+		// Punctuation ends a span, so the numbers are not joined
+		{"twenty, three dollars", []Span{{0, 0, "20,"}, {1, 2, "$3"}}},
+		{"no numbers here", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := Normalize(strings.Fields(tt.text))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Normalize(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	r := types.ChunkResult{
+		StartTime:   10,
+		EndTime:     14,
+		Text:        "it costs twenty three dollars today",
+		Tokens:      []string{" it", " costs", " twen", "ty", " three", " dollars", " today"},
+		Timestamps:  []float32{0, 0.3, 0.8, 1.0, 1.2, 1.6, 2.4},
+		Durations:   []float32{0.2, 0.4, 0.2, 0.2, 0.3, 0.5, 0.4},
+		Confidences: []float32{0.9, 0.9, 0.8, 0.8, 0.5, 0.8, 0.9},
+	}
+	r.UpdateWords()
+	Apply(&r)
+
+	if r.Text != "it costs $23 today" {
+		t.Errorf("Text = %q, want %q", r.Text, "it costs $23 today")
+	}
+	if r.RawText != "it costs twenty three dollars today" {
+		t.Errorf("RawText = %q", r.RawText)
+	}
+	if want := []string{" it", " costs", " $23", " today"}; !reflect.DeepEqual(r.Tokens, want) {
+		t.Errorf("Tokens = %q, want %q", r.Tokens, want)
+	}
+	// The span starts with its first token and ends with its last
+	if want := []float32{0, 0.3, 0.8, 2.4}; !reflect.DeepEqual(r.Timestamps, want) {
+		t.Errorf("Timestamps = %v, want %v", r.Timestamps, want)
+	}
+	if len(r.Durations) != 4 || math.Abs(float64(r.Durations[2])-1.3) > 1e-6 {
+		t.Errorf("Durations = %v, want the span to last 1.3s", r.Durations)
+	}
+	wantConf := float32(math.Pow(0.8*0.8*0.5*0.8, 0.25))
+	if len(r.Confidences) != 4 || math.Abs(float64(r.Confidences[2]-wantConf)) > 1e-6 {
+		t.Errorf("Confidences = %v, want the span's geometric mean %v", r.Confidences, wantConf)
+	}
+
+	if len(r.Words) != 4 {
+		t.Fatalf("Words = %+v, want 4", r.Words)
+	}
+	w := r.Words[2]
+	if w.Text != "$23" || w.Start != 10.8 || w.End != 12.1 {
+		t.Errorf("span word = %q %v-%v, want $23 10.8-12.1", w.Text, w.Start, w.End)
+	}
+}
+
+func TestApplyWithoutDurations(t *testing.T) {
+	r := types.ChunkResult{
+		EndTime:    3,
+		Text:       "ninety nine percent done",
+		Tokens:     []string{" ninety", " nine", " percent", " done"},
+		Timestamps: []float32{0, 0.4, 0.8, 1.5},
+	}
+	r.UpdateWords()
+	Apply(&r)
+
+	if r.Text != "99% done" {
+		t.Errorf("Text = %q, want %q", r.Text, "99% done")
+	}
+	if want := []float32{0, 1.5}; !reflect.DeepEqual(r.Timestamps, want) {
+		t.Errorf("Timestamps = %v, want %v", r.Timestamps, want)
+	}
+	if r.Durations != nil || r.Confidences != nil {
+		t.Errorf("Durations = %v, Confidences = %v, want none", r.Durations, r.Confidences)
+	}
+}
+
+func TestApplyUnchanged(t *testing.T) {
+	r := types.ChunkResult{
+		Text:       "nothing to change",
+		Tokens:     []string{" nothing", " to", " change"},
+		Timestamps: []float32{0, 0.5, 0.8},
+	}
+	Apply(&r)
+	if r.Text != "nothing to change" || r.RawText != "" {
+		t.Errorf("Text = %q, RawText = %q, want the chunk unchanged", r.Text, r.RawText)
+	}
+}
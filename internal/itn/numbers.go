@@ -0,0 +1,293 @@
+package itn
+
+import (
+	"strconv"
+	"strings"
+)
+
+// wordKind is the grammatical role of a number word
+type wordKind int
+
+const (
+	kindNone wordKind = iota
+	kindZero
+	kindUnit    // one..nine
+	kindTeen    // ten..nineteen
+	kindTens    // twenty..ninety
+	kindHundred // hundred
+	kindScale   // thousand, million, ...
+)
+
+type numberWord struct {
+	value   int64
+	kind    wordKind
+	ordinal bool
+}
+
+var numberWords = map[string]numberWord{
+	"zero": {0, kindZero, false},
+
+	"one": {1, kindUnit, false}, "two": {2, kindUnit, false}, "three": {3, kindUnit, false},
+	"four": {4, kindUnit, false}, "five": {5, kindUnit, false}, "six": {6, kindUnit, false},
+	"seven": {7, kindUnit, false}, "eight": {8, kindUnit, false}, "nine": {9, kindUnit, false},
+
+	"ten": {10, kindTeen, false}, "eleven": {11, kindTeen, false}, "twelve": {12, kindTeen, false},
+	"thirteen": {13, kindTeen, false}, "fourteen": {14, kindTeen, false}, "fifteen": {15, kindTeen, false},
+	"sixteen": {16, kindTeen, false}, "seventeen": {17, kindTeen, false}, "eighteen": {18, kindTeen, false},
+	"nineteen": {19, kindTeen, false},
+
+	"twenty": {20, kindTens, false}, "thirty": {30, kindTens, false}, "forty": {40, kindTens, false},
+	"fifty": {50, kindTens, false}, "sixty": {60, kindTens, false}, "seventy": {70, kindTens, false},
+	"eighty": {80, kindTens, false}, "ninety": {90, kindTens, false},
+
+	"hundred":  {100, kindHundred, false},
+	"thousand": {1_000, kindScale, false},
+	"million":  {1_000_000, kindScale, false},
+	"billion":  {1_000_000_000, kindScale, false},
+	"trillion": {1_000_000_000_000, kindScale, false},
+
+	"first": {1, kindUnit, true}, "second": {2, kindUnit, true}, "third": {3, kindUnit, true},
+	"fourth": {4, kindUnit, true}, "fifth": {5, kindUnit, true}, "sixth": {6, kindUnit, true},
+	"seventh": {7, kindUnit, true}, "eighth": {8, kindUnit, true}, "ninth": {9, kindUnit, true},
+
+	"tenth": {10, kindTeen, true}, "eleventh": {11, kindTeen, true}, "twelfth": {12, kindTeen, true},
+	"thirteenth": {13, kindTeen, true}, "fourteenth": {14, kindTeen, true}, "fifteenth": {15, kindTeen, true},
+	"sixteenth": {16, kindTeen, true}, "seventeenth": {17, kindTeen, true}, "eighteenth": {18, kindTeen, true},
+	"nineteenth": {19, kindTeen, true},
+
+	"twentieth": {20, kindTens, true}, "thirtieth": {30, kindTens, true}, "fortieth": {40, kindTens, true},
+	"fiftieth": {50, kindTens, true}, "sixtieth": {60, kindTens, true}, "seventieth": {70, kindTens, true},
+	"eightieth": {80, kindTens, true}, "ninetieth": {90, kindTens, true},
+
+	"hundredth":  {100, kindHundred, true},
+	"thousandth": {1_000, kindScale, true},
+	"millionth":  {1_000_000, kindScale, true},
+	"billionth":  {1_000_000_000, kindScale, true},
+}
+
+// digitWords are read one digit at a time after "point" and in years
+var digitWords = map[string]byte{
+	"zero": '0', "oh": '0', "one": '1', "two": '2', "three": '3', "four": '4',
+	"five": '5', "six": '6', "seven": '7', "eight": '8', "nine": '9',
+}
+
+// cardinal is a spelled-out number spanning parts [start, end)
+type cardinal struct {
+	value   int64
+	end     int
+	ordinal bool
+}
+
+// cardinal parses the longest well-formed number starting at part i, e.g.
+// "two thousand three hundred and five" or "twenty first"
+func (p *parser) cardinal(i int) (cardinal, bool) {
+	var total, current int64
+	lastScale := int64(-1)
+	prev := kindNone
+	end := i
+	ordinal := false
+
+loop:
+	for j := i; j < len(p.parts) && !ordinal; j++ {
+		if j > i && p.parts[j-1].closed {
+			break
+		}
+		w := p.parts[j].text
+
+		// "and" is allowed inside a number: "one hundred and five"
+		if w == "and" {
+			if (prev == kindHundred || prev == kindScale) && p.continues(j) && isSmallNumber(p.parts[j+1].text) {
+				continue
+			}
+			break
+		}
+
+		nw, ok := numberWords[w]
+		if !ok {
+			break
+		}
+
+		switch nw.kind {
+		case kindZero:
+			if j > i {
+				break loop
+			}
+			return cardinal{value: 0, end: j + 1, ordinal: nw.ordinal}, true
+		case kindUnit:
+			if prev == kindUnit || prev == kindTeen {
+				break loop
+			}
+			current += nw.value
+		case kindTeen, kindTens:
+			if prev == kindUnit || prev == kindTeen || prev == kindTens {
+				break loop
+			}
+			current += nw.value
+		case kindHundred:
+			if current == 0 || current >= 100 {
+				break loop
+			}
+			current *= 100
+		case kindScale:
+			if current == 0 || (lastScale >= 0 && nw.value >= lastScale) {
+				break loop
+			}
+			total += current * nw.value
+			current = 0
+			lastScale = nw.value
+		}
+
+		prev = nw.kind
+		ordinal = nw.ordinal
+		end = j + 1
+	}
+
+	if end == i {
+		return cardinal{}, false
+	}
+	return cardinal{value: total + current, end: end, ordinal: ordinal}, true
+}
+
+func isSmallNumber(w string) bool {
+	nw, ok := numberWords[w]
+	return ok && (nw.kind == kindUnit || nw.kind == kindTeen || nw.kind == kindTens)
+}
+
+// year parses years read as two pairs of digits: "nineteen ninety nine",
+// "twenty twenty four", "nineteen oh five", "nineteen hundred"
+func (p *parser) year(i int) (string, int, bool) {
+	first, ok := numberWords[p.parts[i].text]
+	if !ok || first.ordinal || !p.continues(i) {
+		return "", 0, false
+	}
+	switch {
+	case first.kind == kindTeen && first.value >= 15:
+	case first.value == 20 && !isUnit(p.parts[i+1].text):
+	default:
+		return "", 0, false
+	}
+
+	j := i + 1
+	w := p.parts[j].text
+	switch {
+	case w == "hundred":
+		return strconvInt(first.value) + "00", j + 1, true
+	case w == "oh":
+		if p.continues(j) && isUnit(p.parts[j+1].text) {
+			d := digitWords[p.parts[j+1].text]
+			return strconvInt(first.value) + "0" + string(d), j + 2, true
+		}
+	default:
+		second, ok := p.cardinal(j)
+		if ok && !second.ordinal && second.value >= 10 && second.value < 100 {
+			return strconvInt(first.value*100 + second.value), second.end, true
+		}
+	}
+	return "", 0, false
+}
+
+func isUnit(w string) bool {
+	nw, ok := numberWords[w]
+	return ok && nw.kind == kindUnit && !nw.ordinal
+}
+
+// number is a cardinal, decimal, ordinal or year
+type number struct {
+	value   int64  // integer part
+	frac    string // digits after the decimal point
+	ordinal bool
+	year    string
+	end     int
+}
+
+func (p *parser) number(i int) (number, bool) {
+	if y, end, ok := p.year(i); ok {
+		return number{year: y, end: end}, true
+	}
+
+	c, ok := p.cardinal(i)
+	if !ok {
+		return number{}, false
+	}
+	n := number{value: c.value, ordinal: c.ordinal, end: c.end}
+	if c.ordinal {
+		return n, true
+	}
+
+	// Decimal: "three point one four"
+	if p.continues(n.end-1) && p.parts[n.end].text == "point" {
+		var frac []byte
+		j := n.end + 1
+		for ; j < len(p.parts) && (j == n.end+1 || p.continues(j-1)); j++ {
+			d, ok := digitWords[p.parts[j].text]
+			if !ok {
+				break
+			}
+			frac = append(frac, d)
+		}
+		if len(frac) > 0 {
+			n.frac = string(frac)
+			n.end = j
+		}
+	}
+	return n, true
+}
+
+// digits formats the number for display; grouped adds thousands separators
+func (n number) digits(grouped bool) string {
+	if n.year != "" {
+		return n.year
+	}
+	s := strconvInt(n.value)
+	if grouped {
+		s = groupThousands(s)
+	}
+	if n.frac != "" {
+		s += "." + n.frac
+	}
+	return s
+}
+
+func groupThousands(s string) string {
+	if len(s) <= 3 {
+		return s
+	}
+	var b strings.Builder
+	head := len(s) % 3
+	if head > 0 {
+		b.WriteString(s[:head])
+	}
+	for i := head; i < len(s); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
+
+func ordinalSuffix(v int64) string {
+	if v%100 >= 11 && v%100 <= 13 {
+		return "th"
+	}
+	switch v % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+func strconvInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func twoDigits(v int64) string {
+	if v < 10 {
+		return "0" + strconvInt(v)
+	}
+	return strconvInt(v)
+}
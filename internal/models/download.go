@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -19,37 +20,94 @@ const (
 	DecoderFile = "decoder.int8.onnx"
 	JoinerFile  = "joiner.int8.onnx"
 	TokensFile  = "tokens.txt"
+
+	// BpeVocabFile is optional; it is needed to encode hotwords for BPE models
+	BpeVocabFile = "bpe.vocab"
+
+	PunctuationModelFile = "model.onnx"
+	VADModelFile         = "silero_vad.onnx"
 )
 
-var requiredModelFiles = []string{EncoderFile, DecoderFile, JoinerFile, TokensFile}
+// Spec describes a downloadable model archive
+type Spec struct {
+	Name  string
+	URL   string
+	Files []string // required files, used to validate a model dir
+
+	// ASR models only
+	Kind      string   // KindTransducer or KindWhisper
+	Languages []string // ISO 639-1 codes it transcribes, empty when unknown
+	Translate bool     // can translate to English
+}
+
+// ASR is the default speech recognition model
+var ASR = Spec{
+	Name:  DefaultModelName,
+	URL:   ModelURL,
+	Files: []string{EncoderFile, DecoderFile, JoinerFile, TokensFile},
+	Kind:  KindTransducer,
+	Languages: []string{
+		"bg", "cs", "da", "de", "el", "en", "es", "et", "fi", "fr", "hr", "hu", "it",
+		"lt", "lv", "mt", "nl", "pl", "pt", "ro", "ru", "sk", "sl", "sv", "uk",
+	},
+}
 
-// GetModelPath returns the path to the model directory, downloading if necessary
-func GetModelPath() (string, error) {
-	// 1. Check CHOUGH_MODEL env var
-	if envPath := os.Getenv("CHOUGH_MODEL"); envPath != "" {
-		if isValidModel(envPath) {
-			return envPath, nil
+// Punctuation is the CT-Transformer punctuation model (Chinese and English)
+var Punctuation = Spec{
+	Name:  "sherpa-onnx-punct-ct-transformer-zh-en-vocab272727-2024-04-12",
+	URL:   "https://github.com/k2-fsa/sherpa-onnx/releases/download/punctuation-models/sherpa-onnx-punct-ct-transformer-zh-en-vocab272727-2024-04-12.tar.bz2",
+	Files: []string{PunctuationModelFile},
+}
+
+// VAD is the Silero voice activity detection model, a single file
+var VAD = Spec{
+	Name:  "silero-vad",
+	URL:   "https://github.com/k2-fsa/sherpa-onnx/releases/download/asr-models/silero_vad.onnx",
+	Files: []string{VADModelFile},
+}
+
+// Resolve returns the directory of the model described by spec, downloading
+// it to the cache if necessary. modelPath is a user-configured location, if any.
+func Resolve(spec Spec, modelPath string) (string, error) {
+	// 1. Check configured path
+	if modelPath != "" {
+		if spec.IsValid(modelPath) {
+			return modelPath, nil
 		}
-		fmt.Fprintf(os.Stderr, "Warning: CHOUGH_MODEL=%s not found or invalid\n", envPath)
+		fmt.Fprintf(os.Stderr, "Warning: model %s not found or invalid\n", modelPath)
 	}
 
 	// 2. Check cache directory
-	cacheDir := getCacheDir()
-	modelDir := filepath.Join(cacheDir, "chough", "models", DefaultModelName)
+	modelDir := spec.CacheDir()
 
-	if isValidModel(modelDir) {
+	if spec.IsValid(modelDir) {
 		return modelDir, nil
 	}
 
 	// 3. Download model
 	fmt.Fprintf(os.Stderr, "Downloading model to %s...\n", modelDir)
-	if err := downloadAndExtract(modelDir); err != nil {
+	if err := downloadAndExtract(spec.URL, modelDir); err != nil {
 		return "", fmt.Errorf("failed to download model: %w", err)
 	}
 
 	return modelDir, nil
 }
 
+// CacheDir returns the cache location of the model
+func (s Spec) CacheDir() string {
+	return filepath.Join(getCacheDir(), "chough", "models", s.Name)
+}
+
+// IsValid reports whether path contains all required model files
+func (s Spec) IsValid(path string) bool {
+	for _, file := range s.Files {
+		if _, err := os.Stat(filepath.Join(path, file)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func getCacheDir() string {
 	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
 		return xdg
@@ -61,24 +119,17 @@ func getCacheDir() string {
 	return filepath.Join(home, ".cache")
 }
 
-func isValidModel(path string) bool {
-	for _, file := range requiredModelFiles {
-		if _, err := os.Stat(filepath.Join(path, file)); err != nil {
-			return false
-		}
-	}
-	return true
-}
-
-func downloadAndExtract(targetDir string) error {
-	tmpFile, err := os.CreateTemp("", "chough-model-*.tar.bz2")
+// downloadAndExtract downloads a .tar.bz2 archive and extracts it to
+// targetDir, or places any other file in targetDir as it is
+func downloadAndExtract(url, targetDir string) error {
+	tmpFile, err := os.CreateTemp("", "chough-model-*")
 	if err != nil {
 		return err
 	}
 	tmpPath := tmpFile.Name()
 	defer os.Remove(tmpPath)
 
-	resp, err := http.Get(ModelURL)
+	resp, err := http.Get(url)
 	if err != nil {
 		return err
 	}
@@ -127,6 +178,14 @@ func downloadAndExtract(targetDir string) error {
 	}
 	fmt.Fprintln(os.Stderr) // New line after progress
 
+	if !strings.HasSuffix(url, ".tar.bz2") {
+		if err := copyFile(tmpPath, filepath.Join(targetDir, path.Base(url))); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Model ready\n")
+		return nil
+	}
+
 	fmt.Fprintf(os.Stderr, "Extracting...\n")
 
 	if err := extractTarBz2(tmpPath, targetDir); err != nil {
@@ -137,6 +196,28 @@ func downloadAndExtract(targetDir string) error {
 	return nil
 }
 
+// copyFile copies src to dst, creating the directory of dst
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
 func extractTarBz2(archivePath, targetDir string) error {
 	if err := os.MkdirAll(targetDir, 0755); err != nil {
 		return err
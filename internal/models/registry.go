@@ -0,0 +1,143 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ASR model kinds, as named by sherpa-onnx
+const (
+	KindTransducer = "nemo_transducer"
+	KindWhisper    = "whisper"
+)
+
+const asrModelsURL = "https://github.com/k2-fsa/sherpa-onnx/releases/download/asr-models/"
+
+// WhisperLanguages lists the languages of the multilingual Whisper models
+var WhisperLanguages = []string{
+	"af", "am", "ar", "as", "az", "ba", "be", "bg", "bn", "bo", "br", "bs", "ca", "cs", "cy",
+	"da", "de", "el", "en", "es", "et", "eu", "fa", "fi", "fo", "fr", "gl", "gu", "ha", "haw",
+	"he", "hi", "hr", "ht", "hu", "hy", "id", "is", "it", "ja", "jw", "ka", "kk", "km", "kn",
+	"ko", "la", "lb", "ln", "lo", "lt", "lv", "mg", "mi", "mk", "ml", "mn", "mr", "ms", "mt",
+	"my", "ne", "nl", "nn", "no", "oc", "pa", "pl", "ps", "pt", "ro", "ru", "sa", "sd", "si",
+	"sk", "sl", "sn", "so", "sq", "sr", "su", "sv", "sw", "ta", "te", "tg", "th", "tk", "tl",
+	"tr", "tt", "uk", "ur", "uz", "vi", "yi", "yo", "yue", "zh",
+}
+
+// whisper describes a multilingual sherpa-onnx Whisper export
+func whisper(size string, translate bool) Spec {
+	name := "sherpa-onnx-whisper-" + size
+	return Spec{
+		Name:      name,
+		URL:       asrModelsURL + name + ".tar.bz2",
+		Files:     []string{size + "-encoder.int8.onnx", size + "-decoder.int8.onnx", size + "-tokens.txt"},
+		Kind:      KindWhisper,
+		Languages: WhisperLanguages,
+		Translate: translate,
+	}
+}
+
+// Registry lists the known ASR models. Turbo was not trained to translate.
+var Registry = []Spec{
+	ASR,
+	whisper("base", true),
+	whisper("small", true),
+	whisper("medium", true),
+	whisper("large-v3", true),
+	whisper("turbo", false),
+}
+
+// LanguageID is the multilingual Whisper model used for spoken language
+// identification
+var LanguageID = whisper("tiny", true)
+
+// Find returns the registry model called name, with or without the
+// "sherpa-onnx-" prefix
+func Find(name string) (Spec, bool) {
+	for _, spec := range Registry {
+		if name == spec.Name || "sherpa-onnx-"+name == spec.Name {
+			return spec, true
+		}
+	}
+	return Spec{}, false
+}
+
+// Lookup returns the registry entry of model, a registry name or a model
+// directory matched by its name. An empty model is the default.
+func Lookup(model string) (Spec, bool) {
+	if model == "" {
+		return ASR, true
+	}
+	return Find(filepath.Base(filepath.Clean(model)))
+}
+
+// SupportsLanguage reports whether the model transcribes lang. Models with
+// unknown languages accept any.
+func (s Spec) SupportsLanguage(lang string) bool {
+	return len(s.Languages) == 0 || slices.Contains(s.Languages, lang)
+}
+
+// ResolveASR returns the directory and description of the ASR model named
+// by model: a registry name, a model directory, or "" for the default. It
+// downloads registry models to the cache if necessary.
+func ResolveASR(model string) (string, Spec, error) {
+	if spec, ok := Find(model); ok && !isDir(model) {
+		dir, err := Resolve(spec, "")
+		return dir, spec, err
+	}
+
+	if model != "" {
+		if spec, ok := Lookup(model); ok && spec.IsValid(model) {
+			return model, spec, nil
+		}
+		if kind := DetectKind(model); kind != "" {
+			return model, Spec{Name: filepath.Base(model), Kind: kind}, nil
+		}
+		fmt.Fprintf(os.Stderr, "Warning: model %s not found or invalid\n", model)
+	}
+
+	dir, err := Resolve(ASR, "")
+	return dir, ASR, err
+}
+
+// DetectKind returns the kind of the model in dir from its files, or "" if
+// it holds no model chough can load
+func DetectKind(dir string) string {
+	if ASR.IsValid(dir) {
+		return KindTransducer
+	}
+	if _, _, _, ok := WhisperFiles(dir); ok {
+		return KindWhisper
+	}
+	return ""
+}
+
+// WhisperFiles returns the encoder, decoder and tokens of the Whisper export
+// in dir ("<size>-encoder.int8.onnx", ...), preferring int8 weights
+func WhisperFiles(dir string) (encoder, decoder, tokens string, ok bool) {
+	for _, suffix := range []string{"-encoder.int8.onnx", "-encoder.onnx"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*"+suffix))
+		for _, m := range matches {
+			prefix := strings.TrimSuffix(m, suffix)
+			decoder = prefix + strings.Replace(suffix, "encoder", "decoder", 1)
+			tokens = prefix + "-tokens.txt"
+			if isFile(decoder) && isFile(tokens) {
+				return m, decoder, tokens, true
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+const assHeader = `[Script Info]
+ScriptType: v4.00+
+PlayResX: 1920
+PlayResY: 1080
+WrapStyle: 2
+ScaledBorderAndShadow: yes
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,Arial,64,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,1,2,80,80,60,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+`
+
+// WriteASS writes Advanced SubStation Alpha (ASS/SSA v4+) output with a
+// single default style. Cue lines are kept as hard line breaks.
+func WriteASS(out io.Writer, results []types.ChunkResult, opts CueOptions) error {
+	if _, err := io.WriteString(out, assHeader); err != nil {
+		return err
+	}
+
+	for _, cue := range BuildCues(results, opts) {
+		if _, err := fmt.Fprintf(out, "Dialogue: 0,%s,%s,Default,%s,0,0,0,,%s\n",
+			FormatASSTime(cue.Start), FormatASSTime(cue.End), cue.Speaker, escapeASS(cue.Text)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// escapeASS keeps text from being read as override tags, and turns line
+// breaks into \N
+func escapeASS(text string) string {
+	r := strings.NewReplacer("{", "(", "}", ")", "\\", "/", "\n", `\N`)
+	return r.Replace(text)
+}
+
+// FormatASSTime formats seconds as an ASS timestamp (H:MM:SS.cc)
+func FormatASSTime(seconds float64) string {
+	total := int(math.Round(seconds * 100))
+	h := total / 360000
+	m := (total % 360000) / 6000
+	s := (total % 6000) / 100
+	cs := total % 100
+	return fmt.Sprintf("%d:%02d:%02d.%02d", h, m, s, cs)
+}
@@ -0,0 +1,37 @@
+package output
+
+import "testing"
+
+func TestEscapeASS(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"{\\b1}bold{\\b0}", "(/b1)bold(/b0)"},
+		{"a\\Nb", "a/Nb"},
+		{"two\nlines", `two\Nlines`},
+	}
+	for _, tt := range tests {
+		if got := escapeASS(tt.text); got != tt.want {
+			t.Errorf("escapeASS(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFormatASSTime(t *testing.T) {
+	tests := []struct {
+		seconds float64
+		want    string
+	}{
+		{0, "0:00:00.00"},
+		{1.234, "0:00:01.23"},
+		{59.996, "0:01:00.00"},
+		{3725.5, "1:02:05.50"},
+	}
+	for _, tt := range tests {
+		if got := FormatASSTime(tt.seconds); got != tt.want {
+			t.Errorf("FormatASSTime(%v) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package output
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// Actions for words below Options.MinConfidence
+const (
+	LowConfidenceMark = "mark"
+	LowConfidenceDrop = "drop"
+)
+
+// lowConfidenceMarker is appended to marked words
+const lowConfidenceMarker = "(?)"
+
+// ApplyMinConfidence returns a copy of r with words below minConfidence
+// marked or dropped. Chunks without per-token confidences are unchanged.
+func ApplyMinConfidence(r types.ChunkResult, minConfidence float32, action string) types.ChunkResult {
+	if minConfidence <= 0 || len(r.Tokens) == 0 || len(r.Confidences) < len(r.Tokens) {
+		return r
+	}
+
+	// Map each token to its word, -1 for tokens outside any word
+	words := types.GroupWords(r)
+	wordOf := make([]int, len(r.Tokens))
+	for i := range wordOf {
+		wordOf[i] = -1
+	}
+	for wi, w := range words {
+		for t := w.FirstToken; t <= w.LastToken; t++ {
+			wordOf[t] = wi
+		}
+	}
+
+	out := r
+	out.Tokens = make([]string, 0, len(r.Tokens))
+	out.Timestamps = make([]float32, 0, len(r.Timestamps))
+	out.Confidences = make([]float32, 0, len(r.Confidences))
+	if r.Durations != nil {
+		out.Durations = make([]float32, 0, len(r.Durations))
+	}
+
+	for i, tok := range r.Tokens {
+		if i >= len(r.Timestamps) {
+			break
+		}
+		if wi := wordOf[i]; wi >= 0 && words[wi].Confidence < minConfidence {
+			if action == LowConfidenceDrop {
+				continue
+			}
+			if i == lastWordToken(r.Tokens, words[wi]) {
+				tok += lowConfidenceMarker
+			}
+		}
+		out.Tokens = append(out.Tokens, tok)
+		out.Timestamps = append(out.Timestamps, r.Timestamps[i])
+		out.Confidences = append(out.Confidences, r.Confidences[i])
+		if i < len(r.Durations) {
+			out.Durations = append(out.Durations, r.Durations[i])
+		}
+	}
+
+	out.Text = strings.TrimSpace(strings.Join(out.Tokens, ""))
+	out.UpdateWords()
+	return out
+}
+
+// lastWordToken returns the last token of w that is not trailing punctuation
+func lastWordToken(tokens []string, w types.Word) int {
+	for t := w.LastToken; t > w.FirstToken; t-- {
+		if !isPunctuation(tokens[t]) {
+			return t
+		}
+	}
+	return w.FirstToken
+}
+
+func isPunctuation(tok string) bool {
+	tok = strings.TrimSpace(tok)
+	if tok == "" {
+		return false
+	}
+	for _, r := range tok {
+		if !unicode.IsPunct(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,246 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// Cue represents a subtitle cue. Lines are joined with "\n".
+type Cue struct {
+	Start   float64
+	End     float64
+	Text    string
+	Speaker string
+}
+
+// CueOptions controls how transcripts are split into subtitle cues
+type CueOptions struct {
+	MaxDuration  float64 // seconds
+	MinDuration  float64 // seconds, cues are extended into silence
+	MaxLineChars int     // 0 disables wrapping
+	MaxLines     int
+	MaxCPS       float64 // characters per second, 0 disables
+	MinGap       float64 // seconds between cues
+	SplitOnComma bool    // also split after , ; :
+	SplitOnPause float64 // split at pauses longer than this, 0 disables
+
+	Padding           float64 // lead-in and lead-out, seconds
+	ExtendIntoSilence bool    // hold cues until the next one
+}
+
+// DefaultCueOptions returns the default cue rules
+func DefaultCueOptions() CueOptions {
+	return CueOptions{
+		MaxDuration:  5,
+		MaxLineChars: 42,
+		MaxLines:     2,
+	}
+}
+
+// Validate checks that the cue rules are usable
+func (o CueOptions) Validate() error {
+	switch {
+	case o.MaxDuration <= 0:
+		return fmt.Errorf("max cue duration must be positive")
+	case o.MinDuration < 0 || o.MinDuration > o.MaxDuration:
+		return fmt.Errorf("min cue duration must be between 0 and the max cue duration")
+	case o.MaxLineChars < 0:
+		return fmt.Errorf("max line chars must not be negative")
+	case o.MaxLines < 1:
+		return fmt.Errorf("max lines must be at least 1")
+	case o.MaxCPS < 0:
+		return fmt.Errorf("max cps must not be negative")
+	case o.MinGap < 0:
+		return fmt.Errorf("min cue gap must not be negative")
+	case o.SplitOnPause < 0:
+		return fmt.Errorf("split on pause must not be negative")
+	case o.Padding < 0:
+		return fmt.Errorf("cue padding must not be negative")
+	}
+	return nil
+}
+
+// BuildCues splits all chunks into cues with absolute times, then applies
+// the timing rules (padding, min duration, reading speed, extension into
+// silence, min gap) across them
+func BuildCues(results []types.ChunkResult, opts CueOptions) []Cue {
+	var cues []Cue
+	var bounds [][2]float64 // chunk span of each cue, which cues stay within
+
+	for _, r := range results {
+		for _, cue := range GroupTokensIntoCues(r, opts) {
+			if strings.TrimSpace(cue.Text) == "" {
+				continue
+			}
+			cue.Start += r.StartTime
+			cue.End += r.StartTime
+			cues = append(cues, cue)
+			bounds = append(bounds, [2]float64{r.StartTime, r.EndTime})
+		}
+	}
+
+	for i := range cues {
+		c := &cues[i]
+
+		if opts.Padding > 0 {
+			earliest := bounds[i][0]
+			if i > 0 {
+				earliest = max(earliest, cues[i-1].End+opts.MinGap)
+			}
+			c.Start = max(min(c.Start-opts.Padding, c.Start), earliest)
+		}
+
+		limit := bounds[i][1]
+		if i+1 < len(cues) {
+			limit = min(limit, cues[i+1].Start-opts.MinGap)
+		}
+
+		want := c.End + opts.Padding
+		if opts.ExtendIntoSilence {
+			want = limit
+		}
+		if opts.MinDuration > 0 {
+			want = max(want, c.Start+opts.MinDuration)
+		}
+		if opts.MaxCPS > 0 {
+			chars := len([]rune(strings.ReplaceAll(c.Text, "\n", " ")))
+			want = max(want, c.Start+float64(chars)/opts.MaxCPS)
+		}
+		want = min(want, c.Start+opts.MaxDuration)
+
+		// Extend into the following silence, and keep the gap to the next cue
+		c.End = max(min(max(c.End, want), limit), c.Start)
+	}
+
+	return cues
+}
+
+// GroupTokensIntoCues splits a chunk into cues at sentence ends (and commas
+// or pauses if enabled), before a cue would exceed the max duration, and
+// before its text would no longer fit the line limits. Times are relative to
+// the chunk start.
+func GroupTokensIntoCues(r types.ChunkResult, opts CueOptions) []Cue {
+	words := types.GroupWords(r)
+	if len(words) == 0 {
+		if strings.TrimSpace(r.Text) == "" {
+			return nil
+		}
+		return []Cue{{Start: 0, End: r.EndTime - r.StartTime, Text: wrapText(strings.Fields(r.Text), opts), Speaker: r.Speaker}}
+	}
+
+	var cues []Cue
+	var current []types.Word
+
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		texts := make([]string, len(current))
+		for i, w := range current {
+			texts[i] = w.Text
+		}
+		cues = append(cues, Cue{
+			Start:   current[0].Start - r.StartTime,
+			End:     current[len(current)-1].End - r.StartTime,
+			Text:    wrapText(texts, opts),
+			Speaker: r.Speaker,
+		})
+		current = current[:0]
+	}
+
+	for i, w := range words {
+		if len(current) > 0 {
+			prev := words[i-1]
+			switch {
+			case w.End-current[0].Start > opts.MaxDuration:
+				flush()
+			case opts.SplitOnPause > 0 && w.Start-prev.End > opts.SplitOnPause:
+				flush()
+			case !fitsLines(current, w, opts):
+				flush()
+			}
+		}
+
+		current = append(current, w)
+
+		if IsSentenceEnd(w.Text) || (opts.SplitOnComma && isClauseEnd(w.Text)) {
+			flush()
+		}
+	}
+	flush()
+
+	return cues
+}
+
+func isClauseEnd(word string) bool {
+	return strings.HasSuffix(word, ",") || strings.HasSuffix(word, ";") || strings.HasSuffix(word, ":") ||
+		strings.HasSuffix(word, "，") || strings.HasSuffix(word, "；")
+}
+
+// fitsLines reports whether adding w to the cue still fits the line limits
+func fitsLines(current []types.Word, w types.Word, opts CueOptions) bool {
+	if opts.MaxLineChars == 0 {
+		return true
+	}
+	texts := make([]string, 0, len(current)+1)
+	for _, c := range current {
+		texts = append(texts, c.Text)
+	}
+	texts = append(texts, w.Text)
+	return len(greedyLines(texts, opts.MaxLineChars)) <= opts.MaxLines
+}
+
+// wrapText joins words into lines of at most MaxLineChars. Two-line cues
+// are balanced so the lines have similar lengths.
+func wrapText(words []string, opts CueOptions) string {
+	if opts.MaxLineChars == 0 {
+		return strings.Join(words, " ")
+	}
+	lines := greedyLines(words, opts.MaxLineChars)
+	if len(lines) == 2 {
+		lines = balanceLines(words, opts.MaxLineChars)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func greedyLines(words []string, maxChars int) []string {
+	var lines []string
+	line := ""
+	for _, w := range words {
+		switch {
+		case line == "":
+			line = w
+		case runeLen(line)+1+runeLen(w) <= maxChars:
+			line += " " + w
+		default:
+			lines = append(lines, line)
+			line = w
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// balanceLines splits words into two lines within maxChars, minimizing the
+// length of the longer line
+func balanceLines(words []string, maxChars int) []string {
+	best := greedyLines(words, maxChars)
+	bestLen := max(runeLen(best[0]), runeLen(best[1]))
+	for k := 1; k < len(words); k++ {
+		first := strings.Join(words[:k], " ")
+		second := strings.Join(words[k:], " ")
+		longer := max(runeLen(first), runeLen(second))
+		if longer <= maxChars && longer < bestLen {
+			best, bestLen = []string{first, second}, longer
+		}
+	}
+	return best
+}
+
+func runeLen(s string) int {
+	return len([]rune(s))
+}
@@ -0,0 +1,340 @@
+package output
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+func cueTexts(cues []Cue) []string {
+	texts := make([]string, len(cues))
+	for i, c := range cues {
+		texts[i] = c.Text
+	}
+	return texts
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxChars int
+		want     string
+	}{
+		{"fits", "short line", 42, "short line"},
+		{"no wrapping", "a line longer than the limit would allow", 0, "a line longer than the limit would allow"},
+		{"balanced", "the quick brown fox jumps over the lazy dog", 30, "the quick brown fox\njumps over the lazy dog"},
+		{"greedy past two lines", "one two three four five six", 9, "one two\nthree\nfour five\nsix"},
+		{"long word", "supercalifragilistic word", 10, "supercalifragilistic\nword"},
+		{"runes", "ääää ääää ääää", 9, "ääää ääää\nääää"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapText(strings.Fields(tt.text), CueOptions{MaxLineChars: tt.maxChars})
+			if got != tt.want {
+				t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.maxChars, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupTokensIntoCues(t *testing.T) {
+	// One word every 0.5s
+	numbers := types.ChunkResult{
+		EndTime:    3,
+		Text:       "one two three four five six",
+		Tokens:     []string{" one", " two", " three", " four", " five", " six"},
+		Timestamps: []float32{0, 0.5, 1, 1.5, 2, 2.5},
+	}
+	clauses := types.ChunkResult{
+		EndTime:    1.5,
+		Text:       "well, then; fine",
+		Tokens:     []string{" well,", " then;", " fine"},
+		Timestamps: []float32{0, 0.5, 1},
+	}
+
+	tests := []struct {
+		name  string
+		chunk types.ChunkResult
+		opts  CueOptions
+		want  []string
+	}{
+		{
+			name: "sentence ends",
+			chunk: types.ChunkResult{
+				EndTime:    3,
+				Text:       "Hello there. How are you? Fine",
+				Tokens:     []string{" Hello", " there.", " How", " are", " you?", " Fine"},
+				Timestamps: []float32{0, 0.5, 1, 1.5, 2, 2.5},
+			},
+			opts: CueOptions{MaxDuration: 10, MaxLines: 1},
+			want: []string{"Hello there.", "How are you?", "Fine"},
+		},
+		{
+			name:  "max duration",
+			chunk: numbers,
+			opts:  CueOptions{MaxDuration: 1.4, MaxLines: 1},
+			want:  []string{"one two", "three four", "five six"},
+		},
+		{
+			name:  "line limits",
+			chunk: numbers,
+			opts:  CueOptions{MaxDuration: 10, MaxLineChars: 9, MaxLines: 2},
+			want:  []string{"one two\nthree", "four\nfive six"},
+		},
+		{
+			name:  "commas kept by default",
+			chunk: clauses,
+			opts:  CueOptions{MaxDuration: 10, MaxLines: 1},
+			want:  []string{"well, then; fine"},
+		},
+		{
+			name:  "split on comma",
+			chunk: clauses,
+			opts:  CueOptions{MaxDuration: 10, MaxLines: 1, SplitOnComma: true},
+			want:  []string{"well,", "then;", "fine"},
+		},
+		{
+			name: "split on pause",
+			chunk: types.ChunkResult{
+				EndTime:    3.5,
+				Text:       "before the pause",
+				Tokens:     []string{" before", " the", " pause"},
+				Timestamps: []float32{0, 0.5, 3},
+			},
+			opts: CueOptions{MaxDuration: 10, MaxLines: 1, SplitOnPause: 1},
+			want: []string{"before the", "pause"},
+		},
+		{
+			name:  "no tokens",
+			chunk: types.ChunkResult{StartTime: 5, EndTime: 8, Text: "text without tokens"},
+			opts:  CueOptions{MaxDuration: 10, MaxLines: 1},
+			want:  []string{"text without tokens"},
+		},
+		{
+			name:  "silence",
+			chunk: types.ChunkResult{StartTime: 5, EndTime: 8},
+			opts:  CueOptions{MaxDuration: 10, MaxLines: 1},
+			want:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cueTexts(GroupTokensIntoCues(tt.chunk, tt.opts))
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("cues = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCuesTiming(t *testing.T) {
+	// Two words a cue, each word lasting 0.4s of its 0.5s slot
+	twoCues := []types.ChunkResult{{
+		StartTime:  10,
+		EndTime:    20,
+		Text:       "First one. Second one.",
+		Tokens:     []string{" First", " one.", " Second", " one."},
+		Timestamps: []float32{0, 0.5, 1, 1.5},
+		Durations:  []float32{0.4, 0.4, 0.4, 0.4},
+	}}
+
+	tests := []struct {
+		name    string
+		results []types.ChunkResult
+		opts    CueOptions
+		want    [][2]float64
+	}{
+		{
+			name:    "word times",
+			results: twoCues,
+			opts:    CueOptions{MaxDuration: 5, MaxLines: 1},
+			want:    [][2]float64{{10, 10.9}, {11, 11.9}},
+		},
+		{
+			name:    "min duration up to the next cue",
+			results: twoCues,
+			opts:    CueOptions{MaxDuration: 5, MaxLines: 1, MinDuration: 2},
+			want:    [][2]float64{{10, 11}, {11, 13}},
+		},
+		{
+			name:    "max cps",
+			results: twoCues,
+			opts:    CueOptions{MaxDuration: 5, MaxLines: 1, MaxCPS: 5},
+			// "Second one." is 11 characters, 2.2s at 5 per second
+			want: [][2]float64{{10, 11}, {11, 13.2}},
+		},
+		{
+			name:    "max cps within max duration",
+			results: twoCues,
+			opts:    CueOptions{MaxDuration: 1.5, MaxLines: 1, MaxCPS: 1},
+			want:    [][2]float64{{10, 11}, {11, 12.5}},
+		},
+		{
+			name:    "min gap",
+			results: twoCues,
+			opts:    CueOptions{MaxDuration: 5, MaxLines: 1, MinDuration: 2, MinGap: 0.25},
+			want:    [][2]float64{{10, 10.75}, {11, 13}},
+		},
+		{
+			name: "min gap trims touching cues",
+			results: []types.ChunkResult{{
+				EndTime:    10,
+				Text:       "One. Two.",
+				Tokens:     []string{" One.", " Two."},
+				Timestamps: []float32{0, 1},
+				Durations:  []float32{1, 1},
+			}},
+			opts: CueOptions{MaxDuration: 5, MaxLines: 1, MinGap: 0.5},
+			want: [][2]float64{{0, 0.5}, {1, 2}},
+		},
+		{
+			name: "chunk end",
+			results: []types.ChunkResult{
+				{EndTime: 1, Text: "Short.", Tokens: []string{" Short."}, Timestamps: []float32{0}, Durations: []float32{0.5}},
+				{StartTime: 3, EndTime: 6, Text: "Next.", Tokens: []string{" Next."}, Timestamps: []float32{0}, Durations: []float32{0.5}},
+			},
+			opts: CueOptions{MaxDuration: 5, MaxLines: 1, MinDuration: 2},
+			want: [][2]float64{{0, 1}, {3, 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cues := BuildCues(tt.results, tt.opts)
+			if len(cues) != len(tt.want) {
+				t.Fatalf("got %d cues %q, want %d", len(cues), cueTexts(cues), len(tt.want))
+			}
+			for i, c := range cues {
+				if !approx(c.Start, tt.want[i][0]) || !approx(c.End, tt.want[i][1]) {
+					t.Errorf("cue %d %q = %v-%v, want %v-%v", i, c.Text, c.Start, c.End, tt.want[i][0], tt.want[i][1])
+				}
+			}
+		})
+	}
+}
+
+func TestCueOptionsValidate(t *testing.T) {
+	valid := DefaultCueOptions()
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("default options: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*CueOptions)
+	}{
+		{"zero max duration", func(o *CueOptions) { o.MaxDuration = 0 }},
+		{"min above max duration", func(o *CueOptions) { o.MinDuration = o.MaxDuration + 1 }},
+		{"negative min duration", func(o *CueOptions) { o.MinDuration = -1 }},
+		{"negative line chars", func(o *CueOptions) { o.MaxLineChars = -1 }},
+		{"no lines", func(o *CueOptions) { o.MaxLines = 0 }},
+		{"negative cps", func(o *CueOptions) { o.MaxCPS = -1 }},
+		{"negative gap", func(o *CueOptions) { o.MinGap = -1 }},
+		{"negative pause", func(o *CueOptions) { o.SplitOnPause = -1 }},
+		{"negative padding", func(o *CueOptions) { o.Padding = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultCueOptions()
+			tt.modify(&opts)
+			if err := opts.Validate(); err == nil {
+				t.Errorf("Validate() accepted %+v", opts)
+			}
+		})
+	}
+}
+
+func TestBuildCuesPadding(t *testing.T) {
+	// Words end before the pause after them, which padding and extension
+	// may fill
+	pause := []types.ChunkResult{{
+		StartTime:  10,
+		EndTime:    20,
+		Text:       "First. Second. Last.",
+		Tokens:     []string{" First.", " Second.", " Last."},
+		Timestamps: []float32{1, 3, 3.6},
+		Durations:  []float32{0.5, 0.5, 0.4},
+	}}
+
+	tests := []struct {
+		name    string
+		results []types.ChunkResult
+		opts    CueOptions
+		want    [][2]float64
+	}{
+		{
+			name:    "end times from durations",
+			results: pause,
+			opts:    CueOptions{MaxDuration: 5, MaxLines: 1},
+			want:    [][2]float64{{11, 11.5}, {13, 13.5}, {13.6, 14}},
+		},
+		{
+			name: "end times capped without durations",
+			results: []types.ChunkResult{{
+				EndTime:    10,
+				Text:       "One. Two.",
+				Tokens:     []string{" One.", " Two."},
+				Timestamps: []float32{0, 5},
+			}},
+			opts: CueOptions{MaxDuration: 5, MaxLines: 1},
+			want: [][2]float64{{0, types.MaxTokenDuration}, {5, 5 + types.MaxTokenDuration}},
+		},
+		{
+			name:    "padding",
+			results: pause,
+			opts:    CueOptions{MaxDuration: 5, MaxLines: 1, Padding: 0.25},
+			// The last two cues leave no room for padding between them
+			want: [][2]float64{{10.75, 11.75}, {12.75, 13.6}, {13.6, 14.25}},
+		},
+		{
+			name:    "padding keeps the min gap",
+			results: pause,
+			opts:    CueOptions{MaxDuration: 5, MaxLines: 1, Padding: 0.25, MinGap: 0.1},
+			want:    [][2]float64{{10.75, 11.75}, {12.75, 13.5}, {13.6, 14.25}},
+		},
+		{
+			name: "padding within the chunk",
+			results: []types.ChunkResult{{
+				StartTime:  10,
+				EndTime:    11,
+				Text:       "Only.",
+				Tokens:     []string{" Only."},
+				Timestamps: []float32{0.1},
+				Durations:  []float32{0.8},
+			}},
+			opts: CueOptions{MaxDuration: 5, MaxLines: 1, Padding: 0.5},
+			want: [][2]float64{{10, 11}},
+		},
+		{
+			name:    "max duration limits padding, not speech",
+			results: pause,
+			opts:    CueOptions{MaxDuration: 0.6, MaxLines: 1, Padding: 0.5},
+			want:    [][2]float64{{10.5, 11.5}, {12.5, 13.5}, {13.5, 14.1}},
+		},
+		{
+			name:    "extend into silence",
+			results: pause,
+			opts:    CueOptions{MaxDuration: 5, MaxLines: 1, ExtendIntoSilence: true, MinGap: 0.1},
+			want:    [][2]float64{{11, 12.9}, {13, 13.5}, {13.6, 18.6}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cues := BuildCues(tt.results, tt.opts)
+			if len(cues) != len(tt.want) {
+				t.Fatalf("got %d cues %q, want %d", len(cues), cueTexts(cues), len(tt.want))
+			}
+			for i, c := range cues {
+				if !approx(c.Start, tt.want[i][0]) || !approx(c.End, tt.want[i][1]) {
+					t.Errorf("cue %d %q = %v-%v, want %v-%v", i, c.Text, c.Start, c.End, tt.want[i][0], tt.want[i][1])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package output
+
+import (
+	"html/template"
+	"io"
+	"path"
+	"strconv"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// htmlParagraph is a paragraph as rendered by the HTML template
+type htmlParagraph struct {
+	Start   float64
+	End     float64
+	Clock   string
+	Speaker string
+	Words   []htmlWord
+}
+
+type htmlWord struct {
+	Text  string
+	Start string
+	End   string
+	Low   bool // below the min confidence
+}
+
+type htmlPage struct {
+	Title       string
+	AudioSource string
+	Paragraphs  []htmlParagraph
+}
+
+// WriteHTML writes a self-contained HTML transcript: paragraphs of words
+// with data-start/data-end times, an audio player for audioSource (a path
+// relative to the HTML file, or a URL; omitted when empty), highlighting of
+// the current word and a search box. Clicking a word or a paragraph time
+// seeks the player. Words below minConfidence are underlined.
+func WriteHTML(out io.Writer, results []types.ChunkResult, audioSource string, minConfidence float32, opts TextOptions) error {
+	page := htmlPage{Title: "Transcript", AudioSource: audioSource}
+	if audioSource != "" {
+		page.Title = path.Base(audioSource)
+	}
+
+	for _, s := range Paragraphs(results, opts.ParagraphGap) {
+		p := htmlParagraph{
+			Start:   s.Start,
+			End:     s.End,
+			Clock:   FormatClock(s.Start),
+			Speaker: s.Speaker,
+		}
+		for _, w := range s.Words {
+			p.Words = append(p.Words, htmlWord{
+				Text:  w.Text,
+				Start: strconv.FormatFloat(w.Start, 'f', 3, 64),
+				End:   strconv.FormatFloat(w.End, 'f', 3, 64),
+				Low:   minConfidence > 0 && w.Confidence > 0 && w.Confidence < minConfidence,
+			})
+		}
+		page.Paragraphs = append(page.Paragraphs, p)
+	}
+
+	return htmlTemplate.Execute(out, page)
+}
+
+var htmlTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="chough">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; font: 17px/1.6 system-ui, sans-serif; color: #222; background: #fff; }
+header { position: sticky; top: 0; display: flex; gap: 12px; align-items: center; padding: 12px 24px; background: #f6f6f6; border-bottom: 1px solid #ddd; }
+header audio { flex: 1; min-width: 200px; }
+header input { width: 220px; padding: 6px 8px; font: inherit; font-size: 15px; }
+header .count { min-width: 5em; font-size: 14px; color: #666; }
+main { max-width: 46em; margin: 0 auto; padding: 16px 24px 64px; }
+p { margin: 0 0 1.2em; }
+.time { margin-right: 8px; padding: 0; border: 0; background: none; color: #888; font: 13px ui-monospace, monospace; cursor: pointer; }
+.speaker { font-weight: 600; margin-right: 4px; }
+.w { cursor: pointer; border-radius: 3px; }
+.w:hover { background: #eee; }
+.w.low { text-decoration: underline dotted #c60; }
+.w.match { background: #fde68a; }
+.w.current { background: #93c5fd; }
+@media (prefers-color-scheme: dark) {
+  body { color: #ddd; background: #161616; }
+  header { background: #222; border-color: #333; }
+  .w:hover { background: #333; }
+  .w.match { background: #854d0e; }
+  .w.current { background: #1d4ed8; }
+}
+</style>
+</head>
+<body>
+<header>
+{{- if .AudioSource}}
+<audio id="audio" controls preload="metadata" src="{{.AudioSource}}"></audio>
+{{- end}}
+<input id="search" type="search" placeholder="Search (Enter for next)" autocomplete="off">
+<span id="count" class="count"></span>
+</header>
+<main id="transcript">
+{{- range .Paragraphs}}
+<p data-start="{{printf "%.3f" .Start}}" data-end="{{printf "%.3f" .End}}"><button class="time" data-start="{{printf "%.3f" .Start}}">{{.Clock}}</button>
+{{- if .Speaker}}<span class="speaker">{{.Speaker}}:</span>{{end}}
+{{- range .Words}} <span class="w{{if .Low}} low{{end}}" data-start="{{.Start}}" data-end="{{.End}}">{{.Text}}</span>{{end}}</p>
+{{- end}}
+</main>
+<script>
+(function () {
+  var audio = document.getElementById("audio");
+  var words = Array.prototype.slice.call(document.querySelectorAll(".w"));
+  var starts = words.map(function (w) { return parseFloat(w.dataset.start); });
+  var current = null;
+
+  document.getElementById("transcript").addEventListener("click", function (e) {
+    var t = e.target.closest("[data-start]");
+    if (!t || !audio) return;
+    audio.currentTime = parseFloat(t.dataset.start);
+    audio.play();
+  });
+
+  // Highlight the last word that started at or before the playback time
+  function highlight() {
+    var t = audio.currentTime, lo = 0, hi = starts.length - 1, found = -1;
+    while (lo <= hi) {
+      var mid = (lo + hi) >> 1;
+      if (starts[mid] <= t) { found = mid; lo = mid + 1; } else { hi = mid - 1; }
+    }
+    var w = found >= 0 && t <= parseFloat(words[found].dataset.end) + 0.5 ? words[found] : null;
+    if (w === current) return;
+    if (current) current.classList.remove("current");
+    current = w;
+    if (w) w.classList.add("current");
+  }
+  if (audio) {
+    audio.addEventListener("timeupdate", highlight);
+    audio.addEventListener("seeked", highlight);
+  }
+
+  var search = document.getElementById("search");
+  var count = document.getElementById("count");
+  var matches = [], next = 0;
+
+  function normalize(s) { return s.toLowerCase().replace(/[^\p{L}\p{N}\s]/gu, ""); }
+
+  // Match the query against runs of consecutive words
+  search.addEventListener("input", function () {
+    matches.forEach(function (w) { w.classList.remove("match"); });
+    matches = []; next = 0;
+    var q = normalize(search.value).split(/\s+/).filter(Boolean);
+    if (q.length) {
+      var texts = words.map(function (w) { return normalize(w.textContent); });
+      for (var i = 0; i + q.length <= words.length; i++) {
+        var ok = true;
+        for (var j = 0; j < q.length && ok; j++) {
+          ok = j === q.length - 1 ? texts[i + j].indexOf(q[j]) === 0 : texts[i + j] === q[j];
+        }
+        if (ok) for (var k = 0; k < q.length; k++) matches.push(words[i + k]);
+      }
+    }
+    matches.forEach(function (w) { w.classList.add("match"); });
+    count.textContent = q.length ? matches.length / q.length + " found" : "";
+  });
+
+  search.addEventListener("keydown", function (e) {
+    if (e.key !== "Enter" || !matches.length) return;
+    var q = normalize(search.value).split(/\s+/).filter(Boolean).length;
+    var w = matches[(next * q) % matches.length];
+    next = (next + 1) % (matches.length / q);
+    w.scrollIntoView({ block: "center" });
+    if (audio) audio.currentTime = parseFloat(w.dataset.start);
+  });
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+func TestWriteHTML(t *testing.T) {
+	results := []types.ChunkResult{{
+		StartTime:   2,
+		EndTime:     6,
+		Speaker:     "Ann",
+		Tokens:      []string{" <b>bold</b>", " sure", " maybe"},
+		Timestamps:  []float32{0, 0.5, 1},
+		Confidences: []float32{0.9, 0.95, 0.3},
+	}}
+
+	tests := []struct {
+		name        string
+		audioSource string
+		want        []string
+		notWant     []string
+	}{
+		{
+			name:        "with audio",
+			audioSource: "media/talk.mp3",
+			want: []string{
+				"<title>talk.mp3</title>",
+				`<audio id="audio" controls preload="metadata" src="media/talk.mp3">`,
+				`<p data-start="2.000" data-end="4.000">`,
+				`<span class="speaker">Ann:</span>`,
+				`<span class="w" data-start="2.000" data-end="2.500">&lt;b&gt;bold&lt;/b&gt;</span>`,
+				`<span class="w low" data-start="3.000" data-end="4.000">maybe</span>`,
+			},
+			notWant: []string{"<b>bold"},
+		},
+		{
+			name:    "without audio",
+			want:    []string{"<title>Transcript</title>"},
+			notWant: []string{"<audio"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteHTML(&buf, results, tt.audioSource, 0.5, DefaultTextOptions()); err != nil {
+				t.Fatal(err)
+			}
+			html := buf.String()
+			for _, s := range tt.want {
+				if !strings.Contains(html, s) {
+					t.Errorf("missing %s", s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(html, s) {
+					t.Errorf("unexpected %s", s)
+				}
+			}
+		})
+	}
+}
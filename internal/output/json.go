@@ -8,11 +8,14 @@ import (
 )
 
 // WriteJSON writes JSON output
-func WriteJSON(out io.Writer, results []types.ChunkResult, duration float64) error {
+func WriteJSON(out io.Writer, results []types.ChunkResult, duration float64, meta *types.Metadata) error {
 	type Output struct {
 		Duration  float64             `json:"duration_seconds"`
 		Chunks    int                 `json:"chunks"`
 		Text      string              `json:"text"`
+		RawText   string              `json:"raw_text,omitempty"`
+		Language  string              `json:"language,omitempty"`
+		Metadata  *types.Metadata     `json:"metadata,omitempty"`
 		ChunkData []types.ChunkResult `json:"chunk_data,omitempty"`
 	}
 
@@ -20,6 +23,9 @@ func WriteJSON(out io.Writer, results []types.ChunkResult, duration float64) err
 		Duration:  duration,
 		Chunks:    len(results),
 		Text:      FullText(results),
+		RawText:   FullRawText(results),
+		Language:  Language(results),
+		Metadata:  meta,
 		ChunkData: results,
 	}
 
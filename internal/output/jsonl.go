@@ -0,0 +1,98 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// JSONL line types
+const (
+	JSONLChunk   = "chunk"
+	JSONLSummary = "summary"
+	JSONLError   = "error"
+)
+
+// JSONLChunkLine is written as soon as a chunk is transcribed
+type JSONLChunkLine struct {
+	Type  string `json:"type"`
+	Index int    `json:"index"` // from 1
+	types.ChunkResult
+}
+
+// JSONLSummaryLine is the last line of a complete run
+type JSONLSummaryLine struct {
+	Type           string          `json:"type"`
+	Duration       float64         `json:"duration_seconds"`
+	ProcessingTime float64         `json:"processing_time_seconds,omitempty"`
+	RealtimeFactor float64         `json:"realtime_factor,omitempty"`
+	Chunks         int             `json:"chunks"`
+	Text           string          `json:"text"`
+	RawText        string          `json:"raw_text,omitempty"`
+	Language       string          `json:"language,omitempty"`
+	Metadata       *types.Metadata `json:"metadata,omitempty"`
+}
+
+// JSONLErrorLine ends a run that failed part-way
+type JSONLErrorLine struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+// JSONLWriter writes jsonl output incrementally, flushing after each line,
+// so a partial run still leaves usable output
+type JSONLWriter struct {
+	out     io.Writer
+	enc     *json.Encoder
+	results []types.ChunkResult
+}
+
+// NewJSONLWriter returns a writer for jsonl output
+func NewJSONLWriter(out io.Writer) *JSONLWriter {
+	return &JSONLWriter{out: out, enc: json.NewEncoder(out)}
+}
+
+// WriteChunk writes a chunk line
+func (w *JSONLWriter) WriteChunk(r types.ChunkResult) error {
+	w.results = append(w.results, r)
+	return w.write(JSONLChunkLine{Type: JSONLChunk, Index: len(w.results), ChunkResult: r})
+}
+
+// WriteSummary writes the summary line. The chunk count and text are
+// filled in from the chunks written so far.
+func (w *JSONLWriter) WriteSummary(summary JSONLSummaryLine) error {
+	summary.Type = JSONLSummary
+	summary.Chunks = len(w.results)
+	summary.Text = FullText(w.results)
+	summary.RawText = FullRawText(w.results)
+	summary.Language = Language(w.results)
+	return w.write(summary)
+}
+
+// WriteError writes an error line
+func (w *JSONLWriter) WriteError(err error) error {
+	return w.write(JSONLErrorLine{Type: JSONLError, Error: err.Error()})
+}
+
+func (w *JSONLWriter) write(line any) error {
+	if err := w.enc.Encode(line); err != nil {
+		return err
+	}
+	if f, ok := w.out.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// WriteJSONL writes finished results as jsonl
+func WriteJSONL(out io.Writer, results []types.ChunkResult, duration float64, meta *types.Metadata) error {
+	w := NewJSONLWriter(out)
+	for _, r := range results {
+		if err := w.WriteChunk(r); err != nil {
+			return err
+		}
+	}
+	return w.WriteSummary(JSONLSummaryLine{Duration: duration, Metadata: meta})
+}
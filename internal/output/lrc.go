@@ -0,0 +1,39 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// WriteLRC writes LRC lyrics output: one line per cue, with an empty timed
+// line to clear the text where a cue ends before the next one starts
+func WriteLRC(out io.Writer, results []types.ChunkResult, opts CueOptions) error {
+	cues := BuildCues(results, opts)
+	for i, cue := range cues {
+		text := strings.ReplaceAll(cue.Text, "\n", " ")
+		if _, err := fmt.Fprintf(out, "[%s]%s\n", FormatLRCTime(cue.Start), text); err != nil {
+			return err
+		}
+
+		if i+1 == len(cues) || FormatLRCTime(cues[i+1].Start) != FormatLRCTime(cue.End) {
+			if _, err := fmt.Fprintf(out, "[%s]\n", FormatLRCTime(cue.End)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// FormatLRCTime formats seconds as an LRC timestamp (mm:ss.xx). Minutes
+// are not wrapped into hours.
+func FormatLRCTime(seconds float64) string {
+	total := int(math.Round(seconds * 100))
+	m := total / 6000
+	s := (total % 6000) / 100
+	cs := total % 100
+	return fmt.Sprintf("%02d:%02d.%02d", m, s, cs)
+}
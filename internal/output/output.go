@@ -2,17 +2,107 @@ package output
 
 import (
 	"io"
+	"slices"
 
 	"github.com/hyperpuncher/chough/internal/types"
 )
 
+// Formats lists the supported output formats
+var Formats = []string{"text", "sentences", "paragraphs", "json", "jsonl", "vtt", "ass", "ttml", "sbv", "lrc", "audacity", "csv", "tsv", "edl", "markers", "html"}
+
+// IsFormat reports whether format is supported
+func IsFormat(format string) bool {
+	return slices.Contains(Formats, format)
+}
+
+// ContentType returns the HTTP content type of a format
+func ContentType(format string) string {
+	switch format {
+	case "json":
+		return "application/json"
+	case "jsonl":
+		return "application/jsonl"
+	case "vtt":
+		return "text/vtt; charset=utf-8"
+	case "ass":
+		return "text/x-ssa; charset=utf-8"
+	case "ttml":
+		return "application/ttml+xml; charset=utf-8"
+	case "html":
+		return "text/html; charset=utf-8"
+	case "csv", "markers":
+		return "text/csv; charset=utf-8"
+	case "tsv":
+		return "text/tab-separated-values; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Options controls formatting of transcription output
+type Options struct {
+	// Metadata is included in JSON output when set
+	Metadata *types.Metadata
+
+	// MinConfidence marks or drops (per LowConfidence) words below it in
+	// text and subtitle outputs; 0 disables
+	MinConfidence float32
+	LowConfidence string
+
+	// Cues controls subtitle segmentation
+	Cues CueOptions
+
+	// Text controls the segment-based formats
+	Text TextOptions
+
+	// AudioSource is the player source of HTML output: a path relative to
+	// the output file, or a URL
+	AudioSource string
+}
+
 // Write writes formatted output to the given writer
-func Write(out io.Writer, format string, results []types.ChunkResult, duration float64) error {
+func Write(out io.Writer, format string, results []types.ChunkResult, duration float64, opts Options) error {
+	// HTML marks low-confidence words with styling instead of text
+	marked := format == "json" || format == "jsonl" || (format == "html" && opts.LowConfidence != LowConfidenceDrop)
+	if !marked && opts.MinConfidence > 0 {
+		filtered := make([]types.ChunkResult, len(results))
+		for i, r := range results {
+			filtered[i] = ApplyMinConfidence(r, opts.MinConfidence, opts.LowConfidence)
+		}
+		results = filtered
+	}
+
 	switch format {
 	case "json":
-		return WriteJSON(out, results, duration)
+		return WriteJSON(out, results, duration, opts.Metadata)
+	case "jsonl":
+		return WriteJSONL(out, results, duration, opts.Metadata)
 	case "vtt":
-		return WriteVTT(out, results)
+		return WriteVTT(out, results, opts.Cues)
+	case "ass":
+		return WriteASS(out, results, opts.Cues)
+	case "ttml":
+		return WriteTTML(out, results, opts.Cues)
+	case "sbv":
+		return WriteSBV(out, results, opts.Cues)
+	case "lrc":
+		return WriteLRC(out, results, opts.Cues)
+	case "audacity":
+		return WriteAudacity(out, results)
+	case "csv":
+		return WriteTable(out, results, ',')
+	case "tsv":
+		return WriteTable(out, results, '\t')
+	case "edl":
+		return WriteEDL(out, results, opts.Text.FrameRate)
+	case "markers":
+		return WriteMarkers(out, results, opts.Text.FrameRate)
+	case "html":
+		return WriteHTML(out, results, opts.AudioSource, opts.MinConfidence, opts.Text)
+	case "sentences":
+		return WriteSegments(out, Sentences(results), false, opts.Text)
+	case "paragraphs":
+		return WriteSegments(out, Paragraphs(results, opts.Text.ParagraphGap), true, opts.Text)
 	default:
 		return WriteText(out, results)
 	}
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// WriteSBV writes YouTube SubViewer (SBV) output
+func WriteSBV(out io.Writer, results []types.ChunkResult, opts CueOptions) error {
+	for i, cue := range BuildCues(results, opts) {
+		if i > 0 {
+			if _, err := fmt.Fprintln(out); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(out, "%s,%s\n%s\n", FormatSBVTime(cue.Start), FormatSBVTime(cue.End), cue.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatSBVTime formats seconds as an SBV timestamp (H:MM:SS.mmm)
+func FormatSBVTime(seconds float64) string {
+	total := int(math.Round(seconds * 1000))
+	h := total / 3600000
+	m := (total % 3600000) / 60000
+	s := (total % 60000) / 1000
+	ms := total % 1000
+	return fmt.Sprintf("%d:%02d:%02d.%03d", h, m, s, ms)
+}
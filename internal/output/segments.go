@@ -0,0 +1,151 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// TextOptions controls the segment-based formats: sentences, paragraphs
+// and the timeline exports
+type TextOptions struct {
+	// ParagraphGap starts a new paragraph at pauses longer than this (seconds)
+	ParagraphGap float64
+	// Timestamps prefixes each line with its [HH:MM:SS] start time
+	Timestamps bool
+	// FrameRate is the timecode rate of the edl and markers formats
+	FrameRate float64
+}
+
+// DefaultTextOptions returns the default text layout
+func DefaultTextOptions() TextOptions {
+	return TextOptions{ParagraphGap: 2, FrameRate: 30}
+}
+
+// Validate checks that the text layout is usable
+func (o TextOptions) Validate() error {
+	if o.ParagraphGap <= 0 {
+		return fmt.Errorf("paragraph gap must be positive")
+	}
+	if o.FrameRate <= 0 {
+		return fmt.Errorf("frame rate must be positive")
+	}
+	return nil
+}
+
+// Segment is a run of words: a sentence or a paragraph
+type Segment struct {
+	Start      float64
+	End        float64
+	Speaker    string
+	Text       string
+	Confidence float32 // 0 when some words have no confidence
+	Words      []types.Word
+}
+
+// Sentences splits the transcript at sentence ends and speaker changes
+func Sentences(results []types.ChunkResult) []Segment {
+	return segment(results, func(prev, w types.Word) bool {
+		return IsSentenceEnd(prev.Text)
+	})
+}
+
+// Paragraphs splits the transcript at pauses longer than gap and at
+// speaker changes
+func Paragraphs(results []types.ChunkResult, gap float64) []Segment {
+	return segment(results, func(prev, w types.Word) bool {
+		return w.Start-prev.End > gap
+	})
+}
+
+// segment groups the words of all chunks, starting a new segment where
+// split returns true or the speaker changes
+func segment(results []types.ChunkResult, split func(prev, w types.Word) bool) []Segment {
+	var segments []Segment
+	var current Segment
+	var prev types.Word
+
+	flush := func() {
+		if len(current.Words) == 0 {
+			return
+		}
+		texts := make([]string, len(current.Words))
+		confidences := make([]float32, 0, len(current.Words))
+		for i, w := range current.Words {
+			texts[i] = w.Text
+			if w.Confidence > 0 {
+				confidences = append(confidences, w.Confidence)
+			}
+		}
+		current.Text = strings.Join(texts, " ")
+		if len(confidences) == len(texts) {
+			current.Confidence = types.MeanConfidence(confidences)
+		}
+		segments = append(segments, current)
+		current = Segment{}
+	}
+
+	for _, r := range results {
+		for _, w := range chunkWords(r) {
+			if len(current.Words) > 0 && (r.Speaker != current.Speaker || split(prev, w)) {
+				flush()
+			}
+			if len(current.Words) == 0 {
+				current = Segment{Start: w.Start, Speaker: r.Speaker}
+			}
+			current.Words = append(current.Words, w)
+			current.End = w.End
+			prev = w
+		}
+	}
+	flush()
+
+	return segments
+}
+
+// chunkWords returns the chunk's words, or its whole text as one word when
+// it has no token timings (e.g. from an older server)
+func chunkWords(r types.ChunkResult) []types.Word {
+	if len(r.Words) > 0 {
+		return r.Words
+	}
+	if words := types.GroupWords(r); len(words) > 0 {
+		return words
+	}
+	if text := strings.TrimSpace(r.Text); text != "" {
+		return []types.Word{{Text: text, Start: r.StartTime, End: r.EndTime}}
+	}
+	return nil
+}
+
+// WriteSegments writes one segment per line; paragraphs are separated by a
+// blank line
+func WriteSegments(out io.Writer, segments []Segment, paragraphs bool, opts TextOptions) error {
+	for i, s := range segments {
+		if paragraphs && i > 0 {
+			if _, err := fmt.Fprintln(out); err != nil {
+				return err
+			}
+		}
+
+		line := s.Text
+		if s.Speaker != "" {
+			line = s.Speaker + ": " + line
+		}
+		if opts.Timestamps {
+			line = "[" + FormatClock(s.Start) + "] " + line
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatClock formats seconds as HH:MM:SS
+func FormatClock(seconds float64) string {
+	total := int(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, (total%3600)/60, total%60)
+}
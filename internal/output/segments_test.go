@@ -0,0 +1,114 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+func TestSentences(t *testing.T) {
+	results := []types.ChunkResult{
+		{
+			StartTime:  0,
+			EndTime:    4,
+			Speaker:    "A",
+			Tokens:     []string{" Hello", " there.", " How", " are", " you?"},
+			Timestamps: []float32{0, 0.5, 1, 1.5, 2},
+		},
+		{
+			StartTime:  4,
+			EndTime:    8,
+			Speaker:    "B",
+			Tokens:     []string{" Fine", " thanks"},
+			Timestamps: []float32{0, 0.5},
+		},
+		// No token timings, e.g. from an older server
+		{StartTime: 8, EndTime: 10, Speaker: "B", Text: "and you"},
+	}
+	want := []struct {
+		text       string
+		speaker    string
+		start, end float64
+	}{
+		{"Hello there.", "A", 0, 1},
+		{"How are you?", "A", 1, 3},
+		// Words without token timings join the sentence they continue
+		{"Fine thanks and you", "B", 4, 10},
+	}
+
+	got := Sentences(results)
+	if len(got) != len(want) {
+		t.Fatalf("got %d sentences, want %d: %+v", len(got), len(want), got)
+	}
+	for i, s := range got {
+		w := want[i]
+		if s.Text != w.text || s.Speaker != w.speaker || s.Start != w.start || s.End != w.end {
+			t.Errorf("sentence %d = %q %q %v-%v, want %q %q %v-%v", i, s.Text, s.Speaker, s.Start, s.End, w.text, w.speaker, w.start, w.end)
+		}
+	}
+}
+
+func TestParagraphs(t *testing.T) {
+	results := []types.ChunkResult{{
+		StartTime:   10,
+		EndTime:     20,
+		Tokens:      []string{" one", " two", " three", " four"},
+		Timestamps:  []float32{0, 0.5, 4, 4.5},
+		Confidences: []float32{0.9, 0.9, 0.8, 0.2},
+	}}
+
+	tests := []struct {
+		gap  float64
+		want []string
+	}{
+		{2, []string{"one two", "three four"}},
+		{5, []string{"one two three four"}},
+	}
+	for _, tt := range tests {
+		got := Paragraphs(results, tt.gap)
+		if len(got) != len(tt.want) {
+			t.Errorf("gap %v: got %d paragraphs, want %d", tt.gap, len(got), len(tt.want))
+			continue
+		}
+		for i, p := range got {
+			if p.Text != tt.want[i] {
+				t.Errorf("gap %v: paragraph %d = %q, want %q", tt.gap, i, p.Text, tt.want[i])
+			}
+		}
+	}
+
+	// Confidence is the geometric mean of the words
+	p := Paragraphs(results, 2)[1]
+	if p.Confidence < 0.39 || p.Confidence > 0.41 {
+		t.Errorf("confidence = %v, want 0.4", p.Confidence)
+	}
+}
+
+func TestWriteSegments(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, Text: "First."},
+		{Start: 3725, Speaker: "B", Text: "Second."},
+	}
+	tests := []struct {
+		name       string
+		paragraphs bool
+		opts       TextOptions
+		want       string
+	}{
+		{"sentences", false, TextOptions{}, "First.\nB: Second.\n"},
+		{"paragraphs", true, TextOptions{}, "First.\n\nB: Second.\n"},
+		{"timestamps", false, TextOptions{Timestamps: true}, "[00:00:00] First.\n[01:02:05] B: Second.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteSegments(&buf, segments, tt.paragraphs, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
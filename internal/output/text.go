@@ -3,6 +3,7 @@ package output
 import (
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 
 	"github.com/hyperpuncher/chough/internal/types"
@@ -20,8 +21,66 @@ func FullText(results []types.ChunkResult) string {
 	return strings.Join(parts, " ")
 }
 
-// WriteText writes plain text output
+// FullRawText joins the chunks' text before normalization, or returns ""
+// if no chunk was normalized
+func FullRawText(results []types.ChunkResult) string {
+	raw := make([]types.ChunkResult, len(results))
+	normalized := false
+	for i, r := range results {
+		if r.RawText != "" {
+			r.Text = r.RawText
+			normalized = true
+		}
+		raw[i] = r
+	}
+	if !normalized {
+		return ""
+	}
+	return FullText(raw)
+}
+
+// Language returns the language spoken for most of the transcript, by chunk
+// duration, or "" if no chunk has one
+func Language(results []types.ChunkResult) string {
+	spoken := map[string]float64{}
+	best := ""
+	for _, r := range results {
+		if r.Language == "" {
+			continue
+		}
+		spoken[r.Language] += r.EndTime - r.StartTime
+		if best == "" || spoken[r.Language] > spoken[best] {
+			best = r.Language
+		}
+	}
+	return best
+}
+
+// WriteText writes plain text output: one line, or one line per speaker
+// turn prefixed with the speaker when chunks have speakers
 func WriteText(out io.Writer, results []types.ChunkResult) error {
-	_, err := fmt.Fprintln(out, FullText(results))
-	return err
+	if !slices.ContainsFunc(results, func(r types.ChunkResult) bool { return r.Speaker != "" }) {
+		_, err := fmt.Fprintln(out, FullText(results))
+		return err
+	}
+
+	// Silent chunks would split a turn
+	results = slices.DeleteFunc(slices.Clone(results), func(r types.ChunkResult) bool {
+		return strings.TrimSpace(r.Text) == ""
+	})
+	for len(results) > 0 {
+		turn := 1
+		for turn < len(results) && results[turn].Speaker == results[0].Speaker {
+			turn++
+		}
+		line := FullText(results[:turn])
+		if results[0].Speaker != "" {
+			line = results[0].Speaker + ": " + line
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+		results = results[turn:]
+	}
+	return nil
 }
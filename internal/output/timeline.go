@@ -0,0 +1,116 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// WriteAudacity writes an Audacity label track: start, end and label
+// separated by tabs
+func WriteAudacity(out io.Writer, results []types.ChunkResult) error {
+	for _, s := range Sentences(results) {
+		label := s.Text
+		if s.Speaker != "" {
+			label = s.Speaker + ": " + label
+		}
+		label = strings.NewReplacer("\t", " ", "\n", " ").Replace(label)
+		if _, err := fmt.Fprintf(out, "%.6f\t%.6f\t%s\n", s.Start, s.End, label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteTable writes sentences as CSV, or TSV when sep is a tab, with a
+// header row. Times are in seconds; confidence is empty when unknown.
+func WriteTable(out io.Writer, results []types.ChunkResult, sep rune) error {
+	w := csv.NewWriter(out)
+	w.Comma = sep
+
+	if err := w.Write([]string{"start", "end", "speaker", "text", "confidence"}); err != nil {
+		return err
+	}
+	for _, s := range Sentences(results) {
+		confidence := ""
+		if s.Confidence > 0 {
+			confidence = strconv.FormatFloat(float64(s.Confidence), 'f', 3, 32)
+		}
+		row := []string{
+			strconv.FormatFloat(s.Start, 'f', 3, 64),
+			strconv.FormatFloat(s.End, 'f', 3, 64),
+			s.Speaker,
+			s.Text,
+			confidence,
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// WriteEDL writes a CMX3600 edit decision list with one audio event per
+// sentence, its text in a comment line. Record times match source times.
+func WriteEDL(out io.Writer, results []types.ChunkResult, fps float64) error {
+	if _, err := fmt.Fprintf(out, "TITLE: chough transcript\nFCM: NON-DROP FRAME\n\n"); err != nil {
+		return err
+	}
+
+	for i, s := range Sentences(results) {
+		in, outTC := FormatTimecode(s.Start, fps), FormatTimecode(s.End, fps)
+		text := s.Text
+		if s.Speaker != "" {
+			text = s.Speaker + ": " + text
+		}
+		if _, err := fmt.Fprintf(out, "%03d  AX       A     C        %s %s %s %s\n* COMMENT: %s\n\n",
+			i+1, in, outTC, in, outTC, strings.ReplaceAll(text, "\n", " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteMarkers writes a marker CSV in the column layout of Premiere Pro's
+// marker export, which Resolve can also map on import
+func WriteMarkers(out io.Writer, results []types.ChunkResult, fps float64) error {
+	w := csv.NewWriter(out)
+
+	if err := w.Write([]string{"Marker Name", "Description", "In", "Out", "Duration", "Marker Type"}); err != nil {
+		return err
+	}
+	for _, s := range Sentences(results) {
+		row := []string{
+			s.Speaker,
+			s.Text,
+			FormatTimecode(s.Start, fps),
+			FormatTimecode(s.End, fps),
+			FormatTimecode(s.End-s.Start, fps),
+			"Comment",
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// FormatTimecode formats seconds as non-drop-frame SMPTE timecode
+// (HH:MM:SS:FF). Fractional rates such as 29.97 count frames at their
+// nominal rate.
+func FormatTimecode(seconds, fps float64) string {
+	base := max(int(math.Round(fps)), 1)
+	frames := int(math.Round(seconds * fps))
+	ff := frames % base
+	total := frames / base
+	return fmt.Sprintf("%02d:%02d:%02d:%02d", total/3600, (total%3600)/60, total%60, ff)
+}
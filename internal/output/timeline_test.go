@@ -0,0 +1,53 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+func TestFormatTimecode(t *testing.T) {
+	tests := []struct {
+		seconds float64
+		fps     float64
+		want    string
+	}{
+		{0, 30, "00:00:00:00"},
+		{1.5, 30, "00:00:01:15"},
+		// Rounded to the nearest frame, carrying into the next second
+		{0.98, 30, "00:00:00:29"},
+		{0.99, 30, "00:00:01:00"},
+		{1.0 / 48, 24, "00:00:00:01"},
+		{3661.04, 25, "01:01:01:01"},
+		// 29.97 counts 30 frames a second
+		{60, 29.97, "00:00:59:28"},
+		{100, 23.976, "00:01:39:22"},
+	}
+	for _, tt := range tests {
+		if got := FormatTimecode(tt.seconds, tt.fps); got != tt.want {
+			t.Errorf("FormatTimecode(%v, %v) = %q, want %q", tt.seconds, tt.fps, got, tt.want)
+		}
+	}
+}
+
+func TestWriteEDL(t *testing.T) {
+	results := []types.ChunkResult{{
+		StartTime:  1,
+		EndTime:    5,
+		Speaker:    "A",
+		Tokens:     []string{" Hello", " there."},
+		Timestamps: []float32{0, 0.5},
+		Durations:  []float32{0.4, 0.5},
+	}}
+	var buf bytes.Buffer
+	if err := WriteEDL(&buf, results, 25); err != nil {
+		t.Fatal(err)
+	}
+	want := "TITLE: chough transcript\nFCM: NON-DROP FRAME\n\n" +
+		"001  AX       A     C        00:00:01:00 00:00:02:00 00:00:01:00 00:00:02:00\n" +
+		"* COMMENT: A: Hello there.\n\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// WriteTTML writes Timed Text Markup Language (TTML, also read as DFXP)
+// output. Cue lines are separated with <br/>, and speakers are declared
+// as ttm:agent metadata that cues refer to.
+func WriteTTML(out io.Writer, results []types.ChunkResult, opts CueOptions) error {
+	cues := BuildCues(results, opts)
+
+	// Agents are numbered in order of first appearance, since speaker
+	// labels need not be valid XML IDs
+	agents := map[string]string{}
+	var head strings.Builder
+	for _, cue := range cues {
+		if cue.Speaker == "" || agents[cue.Speaker] != "" {
+			continue
+		}
+		id := fmt.Sprintf("speaker%d", len(agents)+1)
+		agents[cue.Speaker] = id
+		fmt.Fprintf(&head, "      <ttm:agent xml:id=\"%s\" type=\"person\"><ttm:name type=\"full\">%s</ttm:name></ttm:agent>\n",
+			id, escapeXML(cue.Speaker))
+	}
+
+	header := xml.Header +
+		`<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttm="http://www.w3.org/ns/ttml#metadata" xml:lang="` + escapeXML(Language(results)) + `">` + "\n"
+	if head.Len() > 0 {
+		header += "  <head>\n    <metadata>\n" + head.String() + "    </metadata>\n  </head>\n"
+	}
+	header += "  <body>\n    <div>\n"
+	if _, err := io.WriteString(out, header); err != nil {
+		return err
+	}
+
+	for _, cue := range cues {
+		lines := strings.Split(cue.Text, "\n")
+		for i, line := range lines {
+			lines[i] = escapeXML(line)
+		}
+		agent := ""
+		if id := agents[cue.Speaker]; id != "" {
+			agent = ` ttm:agent="` + id + `"`
+		}
+		if _, err := fmt.Fprintf(out, "      <p begin=\"%s\" end=\"%s\"%s>%s</p>\n",
+			FormatVTTTime(cue.Start), FormatVTTTime(cue.End), agent, strings.Join(lines, "<br/>")); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(out, "    </div>\n  </body>\n</tt>\n")
+	return err
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -3,20 +3,14 @@ package output
 import (
 	"fmt"
 	"io"
+	"math"
 	"strings"
 
 	"github.com/hyperpuncher/chough/internal/types"
 )
 
-// Cue represents a subtitle cue for VTT
-type Cue struct {
-	Start float64
-	End   float64
-	Text  string
-}
-
 // WriteVTT writes WebVTT output
-func WriteVTT(out io.Writer, results []types.ChunkResult) error {
+func WriteVTT(out io.Writer, results []types.ChunkResult, opts CueOptions) error {
 	if _, err := fmt.Fprintln(out, "WEBVTT"); err != nil {
 		return err
 	}
@@ -24,86 +18,41 @@ func WriteVTT(out io.Writer, results []types.ChunkResult) error {
 		return err
 	}
 
-	cueNum := 1
-	for _, r := range results {
-		for _, cue := range GroupTokensIntoCues(r) {
-			if strings.TrimSpace(cue.Text) == "" {
-				continue
-			}
-
-			start := r.StartTime + cue.Start
-			end := r.StartTime + cue.End
-
-			if _, err := fmt.Fprintf(out, "%d\n", cueNum); err != nil {
-				return err
-			}
-			if _, err := fmt.Fprintf(out, "%s --> %s\n", FormatVTTTime(start), FormatVTTTime(end)); err != nil {
-				return err
-			}
-			if _, err := fmt.Fprintln(out, cue.Text); err != nil {
-				return err
-			}
-			if _, err := fmt.Fprintln(out); err != nil {
-				return err
-			}
-
-			cueNum++
+	for i, cue := range BuildCues(results, opts) {
+		if _, err := fmt.Fprintf(out, "%d\n", i+1); err != nil {
+			return err
 		}
-	}
-
-	return nil
-}
-
-// GroupTokensIntoCues groups tokens into subtitle cues
-func GroupTokensIntoCues(r types.ChunkResult) []Cue {
-	if len(r.Tokens) == 0 {
-		return []Cue{{Start: 0, End: r.EndTime - r.StartTime, Text: r.Text}}
-	}
-
-	var cues []Cue
-	var current Cue
-
-	for i, tok := range r.Tokens {
-		if i >= len(r.Timestamps) {
-			break
+		if _, err := fmt.Fprintf(out, "%s --> %s\n", FormatVTTTime(cue.Start), FormatVTTTime(cue.End)); err != nil {
+			return err
 		}
-
-		timestamp := float64(r.Timestamps[i])
-		if current.Text == "" {
-			current.Start = timestamp
+		text := cue.Text
+		if cue.Speaker != "" {
+			text = "<v " + cue.Speaker + ">" + text
 		}
-
-		current.Text += tok
-		current.End = timestamp
-
-		if IsSentenceEnd(tok) || (current.End-current.Start > 5.0) {
-			current.Text = strings.TrimSpace(current.Text)
-			if current.Text != "" {
-				cues = append(cues, current)
-			}
-			current = Cue{}
+		if _, err := fmt.Fprintln(out, text); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(out); err != nil {
+			return err
 		}
 	}
 
-	if current.Text != "" {
-		current.Text = strings.TrimSpace(current.Text)
-		cues = append(cues, current)
-	}
-
-	return cues
+	return nil
 }
 
 // IsSentenceEnd checks if a token ends a sentence
 func IsSentenceEnd(tok string) bool {
 	t := strings.TrimSpace(tok)
-	return strings.HasSuffix(t, ".") || strings.HasSuffix(t, "!") || strings.HasSuffix(t, "?")
+	return strings.HasSuffix(t, ".") || strings.HasSuffix(t, "!") || strings.HasSuffix(t, "?") ||
+		strings.HasSuffix(t, "。") || strings.HasSuffix(t, "！") || strings.HasSuffix(t, "？")
 }
 
 // FormatVTTTime formats seconds as WebVTT timestamp
 func FormatVTTTime(seconds float64) string {
-	h := int(seconds) / 3600
-	m := (int(seconds) % 3600) / 60
-	s := int(seconds) % 60
-	ms := int((seconds - float64(int(seconds))) * 1000)
+	total := int(math.Round(seconds * 1000))
+	h := total / 3600000
+	m := (total % 3600000) / 60000
+	s := (total % 60000) / 1000
+	ms := total % 1000
 	return fmt.Sprintf("%02d:%02d:%02d.%03d", h, m, s, ms)
 }
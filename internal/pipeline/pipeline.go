@@ -0,0 +1,150 @@
+// Package pipeline transcribes an audio stream chunk by chunk: extracting
+// and filtering each chunk of every track, decoding it and post-processing
+// the result. The CLI and the server's workers share it.
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperpuncher/chough/internal/asr"
+	"github.com/hyperpuncher/chough/internal/audio"
+	"github.com/hyperpuncher/chough/internal/itn"
+	"github.com/hyperpuncher/chough/internal/types"
+)
+
+// minChunk is the shortest chunk transcribed in seconds; shorter trailing
+// chunks cannot hold a word
+const minChunk = 0.5
+
+// Options configures Transcribe
+type Options struct {
+	Recognizer *asr.Recognizer
+	Punctuator *asr.Punctuator // nil leaves chunks unpunctuated
+	ITN        bool
+
+	Tracks []audio.Track      // as returned by audio.Tracks
+	Chunk  audio.ChunkOptions // stream and filters; the channel comes from each track
+	Stream asr.StreamOptions
+
+	// OnAnalyze, if set, is called before the filters are measured over
+	// the whole input, which takes a pass over it
+	OnAnalyze func()
+	// OnChunk, if set, is called as chunk i (from 0) of total starts
+	OnChunk func(i, total int)
+	// OnError decides what a chunk that fails to transcribe does: an error
+	// stops the transcription, nil skips the chunk's track. Unset, the
+	// first failure stops it.
+	OnError func(i, total int, err error) error
+	// OnResult, if set, receives each chunk once post-processed, in order;
+	// an error stops the transcription
+	OnResult func(types.ChunkResult) error
+}
+
+// Result is a finished transcription
+type Result struct {
+	Chunks []types.ChunkResult
+	Speech []types.Span // speech spans decoded, with VAD
+}
+
+// Transcribe transcribes each chunk between boundaries of every track of
+// audioFile, punctuating it in the track's context, then normalizes it with
+// ITN. Chunks of several tracks are interleaved by utterance. On error, the
+// chunks finished so far are returned with it.
+func Transcribe(audioFile string, boundaries []float64, opts Options) (*Result, error) {
+	result := &Result{Chunks: make([]types.ChunkResult, 0, len(boundaries)-1)}
+
+	// Noise and loudness are measured over the whole input, so that every
+	// chunk is filtered alike
+	profiles := make([]*audio.FilterProfile, len(opts.Tracks))
+	if f := opts.Chunk.Filters; (f.Denoise || f.Loudnorm) && opts.OnAnalyze != nil {
+		opts.OnAnalyze()
+	}
+	for t, track := range opts.Tracks {
+		chunkOpts := opts.Chunk
+		chunkOpts.Channel = track.Channel
+		profile, err := audio.AnalyzeFilters(audioFile, boundaries, chunkOpts)
+		if err != nil {
+			return result, err
+		}
+		profiles[t] = profile
+	}
+
+	sentenceStart := make([]bool, len(opts.Tracks))
+	for t := range sentenceStart {
+		sentenceStart[t] = true
+	}
+
+	total := len(boundaries) - 1
+	for i := range total {
+		chunkStart := boundaries[i]
+		chunkEnd := boundaries[i+1]
+		if chunkEnd-chunkStart < minChunk && total > 1 {
+			continue
+		}
+		if opts.OnChunk != nil {
+			opts.OnChunk(i, total)
+		}
+
+		chunks := make([]types.ChunkResult, 0, len(opts.Tracks))
+		for t, track := range opts.Tracks {
+			chunkOpts := opts.Chunk
+			chunkOpts.Channel = track.Channel
+			chunkOpts.Profile = profiles[t]
+			decoded, err := transcribeChunk(opts.Recognizer, audioFile, chunkOpts, chunkStart, chunkEnd-chunkStart, opts.Stream)
+			if err != nil {
+				if opts.OnError == nil {
+					return result, fmt.Errorf("failed to transcribe chunk %d: %w", i+1, err)
+				}
+				if err := opts.OnError(i, total, err); err != nil {
+					return result, err
+				}
+				continue
+			}
+
+			for _, span := range decoded.Speech {
+				result.Speech = append(result.Speech, types.Span{Start: chunkStart + span.Start, End: chunkStart + span.End})
+			}
+
+			chunk := decoded.Chunk(chunkStart, chunkEnd)
+			chunk.Speaker = track.Label
+			if opts.Punctuator != nil {
+				sentenceStart[t] = opts.Punctuator.Apply(&chunk, sentenceStart[t])
+			}
+			chunks = append(chunks, chunk)
+		}
+		if len(opts.Tracks) > 1 {
+			chunks = types.Interleave(chunks)
+		}
+
+		for _, chunk := range chunks {
+			if opts.ITN {
+				itn.Apply(&chunk)
+			}
+			if opts.OnResult != nil {
+				if err := opts.OnResult(chunk); err != nil {
+					return result, err
+				}
+			}
+			result.Chunks = append(result.Chunks, chunk)
+		}
+	}
+	return result, nil
+}
+
+// transcribeChunk extracts a chunk to a temporary WAV file and decodes it
+func transcribeChunk(recognizer *asr.Recognizer, audioFile string, chunkOpts audio.ChunkOptions, start, duration float64, opts asr.StreamOptions) (*asr.Result, error) {
+	tmpDir, err := os.MkdirTemp("", "chough-chunk-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	chunkFile := filepath.Join(tmpDir, "chunk.wav")
+	if err := audio.ExtractChunkWAV(audioFile, chunkFile, start, duration, chunkOpts); err != nil {
+		return nil, err
+	}
+
+	return recognizer.Transcribe(chunkFile, opts)
+}
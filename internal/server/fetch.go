@@ -0,0 +1,233 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// errURLNotAllowed is returned for request URLs the URLPolicy rejects
+var errURLNotAllowed = errors.New("url not allowed")
+
+// maxRedirects is how many redirects a URL download follows
+const maxRedirects = 10
+
+// URLPolicy restricts the URLs the server downloads for requests. Hosts are
+// checked when connecting, so every redirect and every address a name
+// resolves to is checked too.
+//
+// Host lists take names, which also match their subdomains, and IP
+// addresses or CIDR networks, which match the addresses connected to.
+type URLPolicy struct {
+	Schemes      []string // http, https
+	AllowHosts   []string // if set, only these hosts are downloaded from
+	DenyHosts    []string // never downloaded from, checked first
+	AllowPrivate bool     // allow loopback, private and link-local addresses
+}
+
+// DefaultURLPolicy allows http and https URLs on public addresses
+func DefaultURLPolicy() URLPolicy {
+	return URLPolicy{Schemes: []string{"http", "https"}}
+}
+
+// Validate checks the schemes and host lists
+func (p URLPolicy) Validate() error {
+	if len(p.Schemes) == 0 {
+		return fmt.Errorf("url schemes must not be empty (valid: http, https)")
+	}
+	for _, scheme := range p.Schemes {
+		if scheme != "http" && scheme != "https" {
+			return fmt.Errorf("unknown url scheme %q (valid: http, https)", scheme)
+		}
+	}
+	for _, entry := range slices.Concat(p.AllowHosts, p.DenyHosts) {
+		if _, _, err := parseHostEntry(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// client returns an HTTP client that only connects where the policy allows.
+// Proxies from the environment are not used, as they would hide the
+// addresses connected to.
+func (p URLPolicy) client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return p.dial(ctx, dialer, network, addr)
+		},
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: time.Minute,
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return p.checkURL(req.URL)
+		},
+	}
+}
+
+// checkURL checks the scheme and host of a URL before it is requested
+func (p URLPolicy) checkURL(u *url.URL) error {
+	if !slices.Contains(p.Schemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("%w: scheme %q (allowed: %s)", errURLNotAllowed, u.Scheme, strings.Join(p.Schemes, ", "))
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("%w: missing host", errURLNotAllowed)
+	}
+	return nil
+}
+
+// dial resolves addr, checks every address it resolves to, and connects to
+// the checked addresses so a second lookup cannot change them
+func (p URLPolicy) dial(ctx context.Context, dialer *net.Dialer, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if err := p.checkHost(host, ip.Unmap()); err != nil {
+			return nil, err
+		}
+	}
+
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// checkHost checks a host name and one of its addresses against the policy
+func (p URLPolicy) checkHost(host string, ip netip.Addr) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if matchHost(p.DenyHosts, host, ip) {
+		return fmt.Errorf("%w: %s is denied", errURLNotAllowed, host)
+	}
+	if len(p.AllowHosts) > 0 && !matchHost(p.AllowHosts, host, ip) {
+		return fmt.Errorf("%w: %s is not on the allowlist", errURLNotAllowed, host)
+	}
+	// An allowed network is an explicit exception to the private ranges
+	if !p.AllowPrivate && isPrivateAddr(ip) && !matchAddr(p.AllowHosts, ip) {
+		if _, err := netip.ParseAddr(host); err == nil {
+			return fmt.Errorf("%w: %s is a private address", errURLNotAllowed, host)
+		}
+		return fmt.Errorf("%w: %s resolves to private address %s", errURLNotAllowed, host, ip)
+	}
+	return nil
+}
+
+// matchHost reports whether a host name or its address matches an entry
+func matchHost(entries []string, host string, ip netip.Addr) bool {
+	for _, entry := range entries {
+		name, prefix, _ := parseHostEntry(entry)
+		if name != "" && (host == name || strings.HasSuffix(host, "."+name)) {
+			return true
+		}
+		if prefix.IsValid() && prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAddr reports whether an address matches an IP or network entry
+func matchAddr(entries []string, ip netip.Addr) bool {
+	for _, entry := range entries {
+		if _, prefix, _ := parseHostEntry(entry); prefix.IsValid() && prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHostEntry parses a host list entry into a lowercase name (a leading
+// "*." is dropped) or an address prefix, a single address being a /32 or /128
+func parseHostEntry(entry string) (string, netip.Prefix, error) {
+	entry = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(entry)), ".")
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return "", netip.Prefix{}, fmt.Errorf("invalid network %q: %w", entry, err)
+		}
+		return "", prefix.Masked(), nil
+	}
+	if ip, err := netip.ParseAddr(strings.Trim(entry, "[]")); err == nil {
+		ip = ip.Unmap()
+		return "", netip.PrefixFrom(ip, ip.BitLen()), nil
+	}
+	name := strings.TrimPrefix(entry, "*.")
+	if name == "" || strings.ContainsAny(name, ":*/ ") {
+		return "", netip.Prefix{}, fmt.Errorf("invalid host %q", entry)
+	}
+	return name, netip.Prefix{}, nil
+}
+
+// reservedPrefixes are special-purpose ranges netip does not classify:
+// "this network", carrier-grade NAT, IETF protocol assignments, benchmarking
+// and the reserved class E range including broadcast, and the IPv6 ranges
+// that reach IPv4 hosts without an address to check: deprecated
+// IPv4-compatible addresses, local-use NAT64 and Teredo
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001::/32"),
+}
+
+// Prefixes of IPv6 addresses that carry an IPv4 address, which is checked
+// in their place: well-known NAT64 (RFC 6052) and 6to4 (RFC 3056)
+var (
+	nat64Prefix     = netip.MustParsePrefix("64:ff9b::/96")
+	sixToFourPrefix = netip.MustParsePrefix("2002::/16")
+)
+
+// isPrivateAddr reports whether ip is loopback, private, link-local (which
+// includes cloud metadata endpoints such as 169.254.169.254), multicast,
+// unspecified or otherwise not a public unicast address
+func isPrivateAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if embedded, ok := embeddedIPv4(ip); ok {
+		ip = embedded
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	return slices.ContainsFunc(reservedPrefixes, func(p netip.Prefix) bool { return p.Contains(ip) })
+}
+
+// embeddedIPv4 returns the IPv4 address a NAT64 or 6to4 address reaches
+func embeddedIPv4(ip netip.Addr) (netip.Addr, bool) {
+	b := ip.As16()
+	switch {
+	case nat64Prefix.Contains(ip):
+		return netip.AddrFrom4([4]byte(b[12:16])), true
+	case sixToFourPrefix.Contains(ip):
+		return netip.AddrFrom4([4]byte(b[2:6])), true
+	}
+	return netip.Addr{}, false
+}
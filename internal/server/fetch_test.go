@@ -0,0 +1,133 @@
+package server
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestCheckHost(t *testing.T) {
+	public := DefaultURLPolicy()
+	tests := []struct {
+		name    string
+		policy  URLPolicy
+		host    string
+		ip      string
+		allowed bool
+	}{
+		{"public address", public, "example.com", "93.184.215.14", true},
+		{"public IPv6", public, "example.com", "2606:2800:21f:cb07:6820:80da:af6b:8b2c", true},
+		{"loopback", public, "localhost", "127.0.0.1", false},
+		{"IPv6 loopback", public, "::1", "::1", false},
+		{"private", public, "intranet", "10.1.2.3", false},
+		{"unique local", public, "intranet", "fd00::1", false},
+		{"metadata", public, "169.254.169.254", "169.254.169.254", false},
+		{"IPv6 link-local", public, "fe80::1", "fe80::1", false},
+		{"unspecified", public, "0.0.0.0", "0.0.0.0", false},
+		{"carrier-grade NAT", public, "cgn", "100.64.1.1", false},
+		{"broadcast", public, "bcast", "255.255.255.255", false},
+		{"IPv4-mapped metadata", public, "mapped", "::ffff:169.254.169.254", false},
+		{"NAT64 metadata", public, "nat64", "64:ff9b::a9fe:a9fe", false},
+		{"NAT64 public", public, "nat64", "64:ff9b::5db8:d70e", true},
+		{"local-use NAT64", public, "nat64", "64:ff9b:1::5db8:d70e", false},
+		{"6to4 metadata", public, "6to4", "2002:a9fe:a9fe::1", false},
+		{"6to4 public", public, "6to4", "2002:5db8:d70e::1", true},
+		{"IPv4-compatible", public, "compat", "::a9fe:a9fe", false},
+		{"Teredo", public, "teredo", "2001:0:4136:e378:8000:63bf:3fff:fdd2", false},
+
+		{"private allowed", URLPolicy{AllowPrivate: true}, "intranet", "10.1.2.3", true},
+		{"allowed name", URLPolicy{AllowHosts: []string{"example.com"}}, "example.com", "93.184.215.14", true},
+		{"allowed subdomain", URLPolicy{AllowHosts: []string{"example.com"}}, "cdn.Example.com.", "93.184.215.14", true},
+		{"wildcard subdomain", URLPolicy{AllowHosts: []string{"*.example.com"}}, "cdn.example.com", "93.184.215.14", true},
+		{"suffix is not a subdomain", URLPolicy{AllowHosts: []string{"example.com"}}, "badexample.com", "93.184.215.14", false},
+		{"not on allowlist", URLPolicy{AllowHosts: []string{"example.com"}}, "example.org", "93.184.215.14", false},
+		{"allowed name still private", URLPolicy{AllowHosts: []string{"media.internal"}}, "media.internal", "10.1.2.3", false},
+		{"allowed network lifts private", URLPolicy{AllowHosts: []string{"10.1.2.0/24"}}, "media.internal", "10.1.2.3", true},
+		{"allowed network elsewhere", URLPolicy{AllowHosts: []string{"10.1.2.0/24"}}, "media.internal", "10.1.3.3", false},
+		{"allowed address", URLPolicy{AllowHosts: []string{"127.0.0.1"}}, "localhost", "127.0.0.1", true},
+		{"denied name", URLPolicy{DenyHosts: []string{"example.com"}}, "www.example.com", "93.184.215.14", false},
+		{"denied network", URLPolicy{DenyHosts: []string{"93.184.0.0/16"}}, "example.com", "93.184.215.14", false},
+		{"deny before allow", URLPolicy{AllowHosts: []string{"example.com"}, DenyHosts: []string{"cdn.example.com"}}, "cdn.example.com", "93.184.215.14", false},
+		{"deny before private", URLPolicy{AllowPrivate: true, DenyHosts: []string{"10.0.0.0/8"}}, "intranet", "10.1.2.3", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.checkHost(tt.host, netip.MustParseAddr(tt.ip).Unmap())
+			if got := err == nil; got != tt.allowed {
+				t.Fatalf("checkHost(%q, %s) = %v, want allowed %v", tt.host, tt.ip, err, tt.allowed)
+			}
+			if err != nil && !errors.Is(err, errURLNotAllowed) {
+				t.Fatalf("checkHost error %v is not errURLNotAllowed", err)
+			}
+		})
+	}
+}
+
+func TestParseHostEntry(t *testing.T) {
+	tests := []struct {
+		entry  string
+		name   string
+		prefix string
+		err    bool
+	}{
+		{entry: "Example.com.", name: "example.com"},
+		{entry: "*.example.com", name: "example.com"},
+		{entry: " media.internal ", name: "media.internal"},
+		{entry: "10.1.2.3", prefix: "10.1.2.3/32"},
+		{entry: "10.1.2.3/24", prefix: "10.1.2.0/24"},
+		{entry: "::ffff:10.1.2.3", prefix: "10.1.2.3/32"},
+		{entry: "[fd00::1]", prefix: "fd00::1/128"},
+		{entry: "fd00::/8", prefix: "fd00::/8"},
+		{entry: "10.0.0.0/33", err: true},
+		{entry: "", err: true},
+		{entry: "*", err: true},
+		{entry: "a*.example.com", err: true},
+		{entry: "example.com:8080", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			name, prefix, err := parseHostEntry(tt.entry)
+			if (err != nil) != tt.err {
+				t.Fatalf("parseHostEntry(%q) error = %v, want error %v", tt.entry, err, tt.err)
+			}
+			if tt.err {
+				return
+			}
+			if name != tt.name {
+				t.Errorf("name = %q, want %q", name, tt.name)
+			}
+			want := netip.Prefix{}
+			if tt.prefix != "" {
+				want = netip.MustParsePrefix(tt.prefix)
+			}
+			if prefix != want {
+				t.Errorf("prefix = %v, want %v", prefix, want)
+			}
+		})
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	entries := []string{"example.com", "*.example.net", "10.0.0.0/8", "2001:db8::1"}
+	tests := []struct {
+		host string
+		ip   string
+		want bool
+	}{
+		{"example.com", "93.184.215.14", true},
+		{"a.b.example.com", "93.184.215.14", true},
+		{"example.net", "93.184.215.14", true},
+		{"cdn.example.net", "93.184.215.14", true},
+		{"notexample.com", "93.184.215.14", false},
+		{"example.com.evil.org", "93.184.215.14", false},
+		{"intranet", "10.9.8.7", true},
+		{"intranet", "11.9.8.7", false},
+		{"v6", "2001:db8::1", true},
+		{"v6", "2001:db8::2", false},
+	}
+	for _, tt := range tests {
+		if got := matchHost(entries, tt.host, netip.MustParseAddr(tt.ip)); got != tt.want {
+			t.Errorf("matchHost(%q, %s) = %v, want %v", tt.host, tt.ip, got, tt.want)
+		}
+	}
+}
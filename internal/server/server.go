@@ -7,15 +7,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hyperpuncher/chough/internal/asr"
+	"github.com/hyperpuncher/chough/internal/audio"
 	"github.com/hyperpuncher/chough/internal/models"
 	"github.com/hyperpuncher/chough/internal/output"
+	"github.com/hyperpuncher/chough/internal/types"
 )
 
 // Server is the HTTP server
@@ -84,24 +88,40 @@ func (s *Server) handleTranscribe(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse request
-	filePath, format, chunkSize, cleanup, err := s.parseRequest(r)
+	params, err := s.parseRequest(r)
 	if err != nil {
 		s.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	if cleanup != nil {
-		defer cleanup()
+	if params.cleanup != nil {
+		defer params.cleanup()
 	}
 
 	// Create job
 	job := &Job{
-		ID:        strconv.FormatInt(time.Now().UnixNano(), 10),
-		FilePath:  filePath,
-		Format:    format,
-		ChunkSize: chunkSize,
-		Result:    make(chan JobResult, 1),
-		Error:     make(chan error, 1),
-		StartTime: time.Now(),
+		ID:             strconv.FormatInt(time.Now().UnixNano(), 10),
+		FilePath:       params.FilePath,
+		Stream:         params.Stream,
+		Format:         params.Format,
+		ChunkSize:      params.ChunkSize,
+		Hotwords:       params.Hotwords,
+		Decoding:       params.Decoding,
+		Punctuate:      params.Punctuate,
+		ITN:            params.ITN,
+		Task:           params.Task,
+		Language:       params.Language,
+		DetectLanguage: params.DetectLanguage,
+		VAD:            params.VAD,
+		Channel:        params.Channel,
+		SplitChannels:  params.SplitChannels,
+		Filters:        params.Filters,
+		Context:        r.Context(),
+		Result:         make(chan JobResult, 1),
+		Error:          make(chan error, 1),
+		StartTime:      time.Now(),
+	}
+	if params.Format == "jsonl" {
+		job.Chunks = make(chan types.ChunkResult)
 	}
 
 	// Submit to pool
@@ -110,10 +130,15 @@ func (s *Server) handleTranscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if job.Chunks != nil {
+		s.streamJSONL(w, job)
+		return
+	}
+
 	// Wait for result
 	select {
 	case result := <-job.Result:
-		s.sendFormattedResponse(w, format, result)
+		s.sendFormattedResponse(w, params, result)
 	case err := <-job.Error:
 		s.sendError(w, http.StatusInternalServerError, err.Error())
 	case <-time.After(10 * time.Minute):
@@ -139,119 +164,455 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (s *Server) parseRequest(r *http.Request) (filePath, format string, chunkSize int, cleanup func(), err error) {
-	format = "text"
-	chunkSize = 60
+// requestParams holds the parsed options of a transcription request
+type requestParams struct {
+	FilePath  string
+	Format    string
+	ChunkSize int
+	Hotwords  []string
+	Decoding  asr.Decoding
+	Punctuate bool
+	ITN       bool
+	Task      string // "" uses the server's
+
+	Language       string
+	DetectLanguage bool
+	VAD            bool
+
+	AudioStream   int
+	Stream        audio.Stream // AudioStream, probed
+	Channel       int
+	SplitChannels bool
+
+	Preprocess string
+	Filters    audio.Filters // explicit filters, resolved against Preprocess
+
+	MinConfidence float32
+	LowConfidence string
+	Cues          output.CueOptions
+	Text          output.TextOptions
+	AudioSource   string // HTML player source
+
+	cleanup func()
+}
+
+func (s *Server) parseRequest(r *http.Request) (*requestParams, error) {
+	params := &requestParams{
+		Format:        "text",
+		ChunkSize:     60,
+		Decoding:      s.options.Decoding,
+		LowConfidence: output.LowConfidenceMark,
+		Cues:          s.options.Cues,
+		Text:          output.DefaultTextOptions(),
+	}
+
+	fail := func(err error) (*requestParams, error) {
+		if params.cleanup != nil {
+			params.cleanup()
+		}
+		return nil, err
+	}
 
 	contentType := r.Header.Get("Content-Type")
 
 	if strings.HasPrefix(contentType, "multipart/form-data") {
 		// Handle file upload
 		if err := r.ParseMultipartForm(s.options.MaxUploadMB * 1024 * 1024); err != nil {
-			return "", "", 0, nil, fmt.Errorf("failed to parse multipart form: %w", err)
+			return nil, fmt.Errorf("failed to parse multipart form: %w", err)
 		}
 
 		file, header, err := r.FormFile("file")
 		if err != nil {
-			return "", "", 0, nil, fmt.Errorf("missing file field: %w", err)
+			return nil, fmt.Errorf("missing file field: %w", err)
 		}
 		defer file.Close()
 
 		// Save to temp file
 		tmpFile, err := os.CreateTemp("", "chough-upload-*-"+filepath.Base(header.Filename))
 		if err != nil {
-			return "", "", 0, nil, fmt.Errorf("failed to create temp file: %w", err)
+			return nil, fmt.Errorf("failed to create temp file: %w", err)
 		}
 
 		if _, err := io.Copy(tmpFile, file); err != nil {
 			tmpFile.Close()
 			os.Remove(tmpFile.Name())
-			return "", "", 0, nil, fmt.Errorf("failed to save file: %w", err)
+			return nil, fmt.Errorf("failed to save file: %w", err)
 		}
 		tmpFile.Close()
 
-		filePath = tmpFile.Name()
-		cleanup = func() { os.Remove(filePath) }
+		filePath := tmpFile.Name()
+		params.FilePath = filePath
+		params.cleanup = func() { os.Remove(filePath) }
+		params.AudioSource = filepath.Base(header.Filename)
 
 		// Parse additional form fields
 		if f := r.FormValue("format"); f != "" {
-			format = strings.ToLower(f)
+			params.Format = strings.ToLower(f)
 		}
 		if c := r.FormValue("chunk_size"); c != "" {
 			if n, err := strconv.Atoi(c); err == nil && n > 0 {
-				chunkSize = n
+				params.ChunkSize = n
+			}
+		}
+		for _, v := range r.MultipartForm.Value["hotwords"] {
+			params.Hotwords = append(params.Hotwords, splitList(v)...)
+		}
+		if m := r.FormValue("decoding_method"); m != "" {
+			params.Decoding.Method = strings.ToLower(m)
+		}
+		if v := r.FormValue("max_active_paths"); v != "" {
+			if params.Decoding.MaxActivePaths, err = strconv.Atoi(v); err != nil {
+				return fail(fmt.Errorf("invalid max_active_paths: %w", err))
+			}
+		}
+		if v := r.FormValue("blank_penalty"); v != "" {
+			f, err := strconv.ParseFloat(v, 32)
+			if err != nil {
+				return fail(fmt.Errorf("invalid blank_penalty: %w", err))
+			}
+			params.Decoding.BlankPenalty = float32(f)
+		}
+		if c := r.FormValue("min_confidence"); c != "" {
+			v, err := strconv.ParseFloat(c, 32)
+			if err != nil {
+				return fail(fmt.Errorf("invalid min_confidence: %w", err))
+			}
+			params.MinConfidence = float32(v)
+		}
+		if l := r.FormValue("low_confidence"); l != "" {
+			params.LowConfidence = strings.ToLower(l)
+		}
+		if t := r.FormValue("task"); t != "" {
+			params.Task = strings.ToLower(t)
+		}
+		if l := r.FormValue("language"); l != "" {
+			params.Language = strings.ToLower(l)
+		}
+		if v := r.FormValue("audio_stream"); v != "" {
+			if params.AudioStream, err = strconv.Atoi(v); err != nil {
+				return fail(fmt.Errorf("invalid audio_stream: %w", err))
 			}
 		}
+		if params.Channel, err = audio.ParseChannel(r.FormValue("channel")); err != nil {
+			return fail(err)
+		}
+		params.Preprocess = r.FormValue("preprocess")
+		for name, dst := range map[string]*bool{"punctuate": &params.Punctuate, "itn": &params.ITN, "detect_language": &params.DetectLanguage, "split_channels": &params.SplitChannels, "vad": &params.VAD, "denoise": &params.Filters.Denoise, "loudnorm": &params.Filters.Loudnorm, "timestamps": &params.Text.Timestamps} {
+			if v := r.FormValue(name); v != "" {
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					return fail(fmt.Errorf("invalid %s: %w", name, err))
+				}
+				*dst = b
+			}
+		}
+		for name, dst := range map[string]*float64{"highpass": &params.Filters.HighPass, "paragraph_gap": &params.Text.ParagraphGap, "frame_rate": &params.Text.FrameRate} {
+			if v := r.FormValue(name); v != "" {
+				f, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					return fail(fmt.Errorf("invalid %s: %w", name, err))
+				}
+				*dst = f
+			}
+		}
+		if a := r.FormValue("audio_source"); a != "" {
+			params.AudioSource = a
+		}
+		if err := parseCueForm(r, &params.Cues); err != nil {
+			return fail(err)
+		}
 
 	} else if strings.HasPrefix(contentType, "application/json") {
 		// Handle JSON request (URL or base64)
 		var req TranscribeRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			return "", "", 0, nil, fmt.Errorf("invalid JSON: %w", err)
+			return nil, fmt.Errorf("invalid JSON: %w", err)
 		}
 
 		if req.URL != "" {
 			// Download from URL
-			filePath, err = s.downloadFromURL(req.URL)
+			filePath, err := s.downloadFromURL(req.URL)
 			if err != nil {
-				return "", "", 0, nil, err
+				return nil, err
 			}
-			cleanup = func() { os.Remove(filePath) }
+			params.FilePath = filePath
+			params.cleanup = func() { os.Remove(filePath) }
+			params.AudioSource = req.URL
 
 		} else if req.Base64 != "" {
 			// Decode base64
 			data, err := base64.StdEncoding.DecodeString(req.Base64)
 			if err != nil {
-				return "", "", 0, nil, fmt.Errorf("invalid base64: %w", err)
+				return nil, fmt.Errorf("invalid base64: %w", err)
 			}
 
 			tmpFile, err := os.CreateTemp("", "chough-b64-*")
 			if err != nil {
-				return "", "", 0, nil, fmt.Errorf("failed to create temp file: %w", err)
+				return nil, fmt.Errorf("failed to create temp file: %w", err)
 			}
 
 			if _, err := tmpFile.Write(data); err != nil {
 				tmpFile.Close()
 				os.Remove(tmpFile.Name())
-				return "", "", 0, nil, fmt.Errorf("failed to write file: %w", err)
+				return nil, fmt.Errorf("failed to write file: %w", err)
 			}
 			tmpFile.Close()
 
-			filePath = tmpFile.Name()
-			cleanup = func() { os.Remove(filePath) }
+			filePath := tmpFile.Name()
+			params.FilePath = filePath
+			params.cleanup = func() { os.Remove(filePath) }
 
 		} else {
-			return "", "", 0, nil, fmt.Errorf("missing url or base64 in request")
+			return nil, fmt.Errorf("missing url or base64 in request")
 		}
 
 		if req.Format != "" {
-			format = strings.ToLower(req.Format)
+			params.Format = strings.ToLower(req.Format)
 		}
 		if req.ChunkSize > 0 {
-			chunkSize = req.ChunkSize
+			params.ChunkSize = req.ChunkSize
+		}
+		params.Hotwords = req.Hotwords
+		if req.DecodingMethod != "" {
+			params.Decoding.Method = strings.ToLower(req.DecodingMethod)
+		}
+		if req.MaxActivePaths != 0 {
+			params.Decoding.MaxActivePaths = req.MaxActivePaths
+		}
+		if req.BlankPenalty != nil {
+			params.Decoding.BlankPenalty = float32(*req.BlankPenalty)
+		}
+		params.MinConfidence = req.MinConfidence
+		if req.LowConfidence != "" {
+			params.LowConfidence = strings.ToLower(req.LowConfidence)
+		}
+		params.Punctuate = req.Punctuate
+		params.ITN = req.ITN
+		params.Task = strings.ToLower(req.Task)
+		params.Language = strings.ToLower(req.Language)
+		params.DetectLanguage = req.DetectLanguage
+		params.VAD = req.VAD
+		channel, err := audio.ParseChannel(req.Channel)
+		if err != nil {
+			return fail(err)
+		}
+		params.Channel = channel
+		params.AudioStream = req.AudioStream
+		params.SplitChannels = req.SplitChannels
+		params.Preprocess = req.Preprocess
+		params.Filters = audio.Filters{HighPass: req.HighPass, Denoise: req.Denoise, Loudnorm: req.Loudnorm}
+		applyCueFields(&req, &params.Cues)
+		if req.ParagraphGap != 0 {
+			params.Text.ParagraphGap = req.ParagraphGap
+		}
+		params.Text.Timestamps = req.Timestamps
+		if req.AudioSource != "" {
+			params.AudioSource = req.AudioSource
+		}
+		if req.FrameRate != 0 {
+			params.Text.FrameRate = req.FrameRate
 		}
 
 	} else {
-		return "", "", 0, nil, fmt.Errorf("unsupported content type: %s", contentType)
+		return nil, fmt.Errorf("unsupported content type: %s", contentType)
 	}
 
 	// Validate format
-	if format != "text" && format != "json" && format != "vtt" {
-		if cleanup != nil {
-			cleanup()
+	if !output.IsFormat(params.Format) {
+		return fail(fmt.Errorf("invalid format: %s (must be one of %s)", params.Format, strings.Join(output.Formats, ", ")))
+	}
+	if err := params.Text.Validate(); err != nil {
+		return fail(err)
+	}
+
+	// Validate confidence filtering
+	if params.MinConfidence < 0 || params.MinConfidence > 1 {
+		return fail(fmt.Errorf("invalid min_confidence: %g (must be between 0 and 1)", params.MinConfidence))
+	}
+	if params.LowConfidence != output.LowConfidenceMark && params.LowConfidence != output.LowConfidenceDrop {
+		return fail(fmt.Errorf("invalid low_confidence: %s (must be mark or drop)", params.LowConfidence))
+	}
+
+	// Validate subtitle rules
+	if err := params.Cues.Validate(); err != nil {
+		return fail(err)
+	}
+
+	// Validate decoding overrides and hotwords
+	method, err := asr.ParseDecodingMethod(params.Decoding.Method)
+	if err != nil {
+		return fail(err)
+	}
+	params.Decoding.Method = method
+	if err := params.Decoding.Validate(s.options.ModelKind); err != nil {
+		return fail(err)
+	}
+	if len(params.Hotwords) > 0 && !params.Decoding.SupportsHotwords() {
+		return fail(fmt.Errorf("hotwords require decoding_method modified_beam_search"))
+	}
+
+	// Validate punctuation
+	if params.Punctuate && !s.options.AllowPunctuation {
+		return fail(fmt.Errorf("punctuate requires the server to run with --punctuate"))
+	}
+
+	// Validate task
+	if params.Task != "" {
+		if _, err := asr.ParseTask(params.Task); err != nil {
+			return fail(err)
+		}
+		if params.Task == asr.TaskTranslate && !s.options.AllowTranslation {
+			return fail(fmt.Errorf("task translate requires the server to run with a Whisper model that translates"))
 		}
-		return "", "", 0, nil, fmt.Errorf("invalid format: %s (must be text, json, or vtt)", format)
 	}
 
-	return filePath, format, chunkSize, cleanup, nil
+	// Validate channels
+	if params.SplitChannels && params.Channel != audio.DownmixChannels {
+		return fail(fmt.Errorf("channel and split_channels are mutually exclusive"))
+	}
+
+	// Resolve preprocessing
+	filters, err := audio.ResolveFilters(params.Preprocess, params.Filters)
+	if err != nil {
+		return fail(err)
+	}
+	params.Filters = filters
+
+	// Validate language
+	if params.Language != "" && len(s.options.Languages) > 0 && !slices.Contains(s.options.Languages, params.Language) {
+		return fail(fmt.Errorf("invalid language: %s (the model supports %s)", params.Language, strings.Join(s.options.Languages, ", ")))
+	}
+	if params.DetectLanguage && !s.options.AllowLanguageID {
+		return fail(fmt.Errorf("detect_language requires the server to run with --detect-language"))
+	}
+
+	// Validate VAD
+	if params.VAD && !s.options.AllowVAD {
+		return fail(fmt.Errorf("vad requires the server to run with --vad"))
+	}
+
+	// Probe the input, so unusable audio is rejected before it is queued
+	probe, err := audio.ProbeFile(params.FilePath)
+	if err != nil {
+		return fail(fmt.Errorf("failed to probe audio: %w", err))
+	}
+	if params.Stream, err = probe.Stream(params.AudioStream); err != nil {
+		return fail(err)
+	}
+	if err := params.Stream.CheckChannel(params.Channel); err != nil {
+		return fail(err)
+	}
+
+	return params, nil
 }
 
-func (s *Server) downloadFromURL(url string) (string, error) {
-	client := &http.Client{
-		Timeout: 5 * time.Minute,
+// parseCueForm overrides the subtitle rules from multipart form fields
+func parseCueForm(r *http.Request, cues *output.CueOptions) error {
+	floats := map[string]*float64{
+		"max_cue_duration": &cues.MaxDuration,
+		"min_cue_duration": &cues.MinDuration,
+		"max_cps":          &cues.MaxCPS,
+		"min_cue_gap":      &cues.MinGap,
+		"split_on_pause":   &cues.SplitOnPause,
+		"cue_padding":      &cues.Padding,
+	}
+	for name, dst := range floats {
+		if v := r.FormValue(name); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dst = f
+		}
 	}
 
-	resp, err := client.Get(url)
+	ints := map[string]*int{
+		"max_line_chars": &cues.MaxLineChars,
+		"max_lines":      &cues.MaxLines,
+	}
+	for name, dst := range ints {
+		if v := r.FormValue(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dst = n
+		}
+	}
+
+	bools := map[string]*bool{
+		"split_on_comma":      &cues.SplitOnComma,
+		"extend_into_silence": &cues.ExtendIntoSilence,
+	}
+	for name, dst := range bools {
+		if v := r.FormValue(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dst = b
+		}
+	}
+	return nil
+}
+
+// applyCueFields overrides the subtitle rules with the fields set in req
+func applyCueFields(req *TranscribeRequest, cues *output.CueOptions) {
+	if req.MaxCueDuration != nil {
+		cues.MaxDuration = *req.MaxCueDuration
+	}
+	if req.MinCueDuration != nil {
+		cues.MinDuration = *req.MinCueDuration
+	}
+	if req.MaxLineChars != nil {
+		cues.MaxLineChars = *req.MaxLineChars
+	}
+	if req.MaxLines != nil {
+		cues.MaxLines = *req.MaxLines
+	}
+	if req.MaxCPS != nil {
+		cues.MaxCPS = *req.MaxCPS
+	}
+	if req.MinCueGap != nil {
+		cues.MinGap = *req.MinCueGap
+	}
+	if req.SplitOnComma != nil {
+		cues.SplitOnComma = *req.SplitOnComma
+	}
+	if req.SplitOnPause != nil {
+		cues.SplitOnPause = *req.SplitOnPause
+	}
+	if req.CuePadding != nil {
+		cues.Padding = *req.CuePadding
+	}
+	if req.ExtendCues != nil {
+		cues.ExtendIntoSilence = *req.ExtendCues
+	}
+}
+
+// splitList splits a comma- or newline-separated form value
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// downloadFromURL downloads a request URL to a temp file, within the
+// server's URL policy and upload size limit
+func (s *Server) downloadFromURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if err := s.options.URLPolicy.checkURL(u); err != nil {
+		return "", err
+	}
+
+	resp, err := s.options.URLPolicy.client(5 * time.Minute).Get(u.String())
 	if err != nil {
 		return "", fmt.Errorf("failed to download: %w", err)
 	}
@@ -261,8 +622,9 @@ func (s *Server) downloadFromURL(url string) (string, error) {
 		return "", fmt.Errorf("download failed: %s", resp.Status)
 	}
 
-	// Check size
-	if resp.ContentLength > s.options.MaxUploadMB*1024*1024 {
+	// Check size, and again while reading as Content-Length may be unset
+	maxBytes := s.options.MaxUploadMB * 1024 * 1024
+	if resp.ContentLength > maxBytes {
 		return "", fmt.Errorf("file too large (max %d MB)", s.options.MaxUploadMB)
 	}
 
@@ -271,12 +633,17 @@ func (s *Server) downloadFromURL(url string) (string, error) {
 		return "", fmt.Errorf("failed to create temp file: %w", err)
 	}
 
-	written, err := io.Copy(tmpFile, io.LimitReader(resp.Body, s.options.MaxUploadMB*1024*1024))
+	written, err := io.Copy(tmpFile, io.LimitReader(resp.Body, maxBytes+1))
 	if err != nil {
 		tmpFile.Close()
 		os.Remove(tmpFile.Name())
 		return "", fmt.Errorf("failed to download: %w", err)
 	}
+	if written > maxBytes {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("file too large (max %d MB)", s.options.MaxUploadMB)
+	}
 
 	if err := tmpFile.Close(); err != nil {
 		os.Remove(tmpFile.Name())
@@ -305,39 +672,131 @@ func (s *Server) sendError(w http.ResponseWriter, status int, message string) {
 	})
 }
 
-func (s *Server) sendFormattedResponse(w http.ResponseWriter, format string, result JobResult) {
-	switch format {
-	case "text":
-		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintln(w, result.Text)
-	case "vtt":
-		w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+// streamJSONL writes each chunk as the worker finishes it, then a summary
+// line, or an error line if the job fails part-way
+func (s *Server) streamJSONL(w http.ResponseWriter, job *Job) {
+	w.Header().Set("Content-Type", output.ContentType("jsonl"))
+	w.WriteHeader(http.StatusOK)
+	stream := output.NewJSONLWriter(w)
+	timeout := time.After(10 * time.Minute)
+
+	for {
+		select {
+		case chunk := <-job.Chunks:
+			if err := stream.WriteChunk(chunk); err != nil {
+				return
+			}
+		case result := <-job.Result:
+			stream.WriteSummary(output.JSONLSummaryLine{
+				Duration:       result.Duration,
+				ProcessingTime: result.ProcessingTime,
+				RealtimeFactor: result.RealtimeFactor,
+				Metadata:       result.Metadata,
+			})
+			return
+		case err := <-job.Error:
+			stream.WriteError(err)
+			return
+		case <-timeout:
+			stream.WriteError(fmt.Errorf("transcription timeout"))
+			return
+		}
+	}
+}
+
+func (s *Server) sendFormattedResponse(w http.ResponseWriter, params *requestParams, result JobResult) {
+	opts := output.Options{
+		Metadata:      result.Metadata,
+		MinConfidence: params.MinConfidence,
+		LowConfidence: params.LowConfidence,
+		Cues:          params.Cues,
+		Text:          params.Text,
+		AudioSource:   params.AudioSource,
+	}
+
+	if params.Format != "json" {
+		w.Header().Set("Content-Type", output.ContentType(params.Format))
 		w.WriteHeader(http.StatusOK)
-		output.WriteVTT(w, result.Chunks)
-	default: // json
-		s.sendJSON(w, http.StatusOK, TranscribeResponse{
-			Success:        true,
-			Duration:       result.Duration,
-			ProcessingTime: result.ProcessingTime,
-			RealtimeFactor: result.RealtimeFactor,
-			Text:           result.Text,
-			Chunks:         result.Chunks,
-		})
+		output.Write(w, params.Format, result.Chunks, result.Duration, opts)
+		return
 	}
+
+	s.sendJSON(w, http.StatusOK, TranscribeResponse{
+		Success:        true,
+		Duration:       result.Duration,
+		ProcessingTime: result.ProcessingTime,
+		RealtimeFactor: result.RealtimeFactor,
+		Text:           result.Text,
+		RawText:        result.RawText,
+		Language:       result.Language,
+		Metadata:       result.Metadata,
+		Chunks:         result.Chunks,
+	})
 }
 
-// LoadRecognizer loads the ASR recognizer
-func LoadRecognizer() (*asr.Recognizer, error) {
-	modelPath, err := models.GetModelPath()
+// LoadRecognizer loads the ASR recognizer, resolving cfg.ModelPath (a
+// registry name or model directory) to the default model if it is empty or
+// invalid
+func LoadRecognizer(cfg *asr.Config) (*asr.Recognizer, error) {
+	modelPath, spec, err := models.ResolveASR(cfg.ModelPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get model: %w", err)
 	}
+	cfg.ModelPath = modelPath
+	cfg.Kind = spec.Kind
 
-	recognizer, err := asr.NewRecognizer(asr.DefaultConfig(modelPath))
+	recognizer, err := asr.NewRecognizer(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load model: %w", err)
 	}
 
 	return recognizer, nil
 }
+
+// LoadLanguageIdentifier loads the language identification model,
+// resolving modelPath to the default model if it is empty or invalid
+func LoadLanguageIdentifier(modelPath, provider string) (*asr.LanguageIdentifier, error) {
+	modelPath, err := models.Resolve(models.LanguageID, modelPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get language identification model: %w", err)
+	}
+
+	identifier, err := asr.NewLanguageIdentifier(modelPath, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load language identification model: %w", err)
+	}
+
+	return identifier, nil
+}
+
+// LoadVAD loads the voice activity detection model, resolving modelPath to
+// the default model if it is empty or invalid
+func LoadVAD(modelPath, provider string) (*asr.VoiceActivityDetector, error) {
+	modelPath, err := models.Resolve(models.VAD, modelPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VAD model: %w", err)
+	}
+
+	vad, err := asr.NewVoiceActivityDetector(modelPath, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load VAD model: %w", err)
+	}
+
+	return vad, nil
+}
+
+// LoadPunctuator loads the punctuation model, resolving modelPath to the
+// default model if it is empty or invalid
+func LoadPunctuator(modelPath, provider string) (*asr.Punctuator, error) {
+	modelPath, err := models.Resolve(models.Punctuation, modelPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get punctuation model: %w", err)
+	}
+
+	punctuator, err := asr.NewPunctuator(modelPath, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load punctuation model: %w", err)
+	}
+
+	return punctuator, nil
+}
@@ -1,21 +1,38 @@
 package server
 
 import (
+	"context"
 	"time"
 
 	"github.com/hyperpuncher/chough/internal/asr"
+	"github.com/hyperpuncher/chough/internal/audio"
+	"github.com/hyperpuncher/chough/internal/output"
 	"github.com/hyperpuncher/chough/internal/types"
 )
 
 // Job represents a transcription job
 type Job struct {
-	ID        string
-	FilePath  string
-	Format    string
-	ChunkSize int
-	Result    chan JobResult
-	Error     chan error
-	StartTime time.Time
+	ID             string
+	FilePath       string
+	Stream         audio.Stream // probed audio stream to transcribe
+	Format         string
+	ChunkSize      int
+	Hotwords       []string
+	Decoding       asr.Decoding // resolved against the server's settings
+	Punctuate      bool
+	ITN            bool
+	Task           string // Whisper task, "" uses the recognizer's
+	Language       string // known spoken language, skips identification
+	DetectLanguage bool
+	VAD            bool // transcribe only detected speech
+	Channel        int  // 0-based, or audio.DownmixChannels
+	SplitChannels  bool
+	Filters        audio.Filters          // preprocessing applied to each chunk
+	Context        context.Context        // canceled when the client goes away
+	Chunks         chan types.ChunkResult // if set, receives each chunk as it is finished
+	Result         chan JobResult
+	Error          chan error
+	StartTime      time.Time
 }
 
 // JobResult holds the result of a transcription job
@@ -24,15 +41,61 @@ type JobResult struct {
 	ProcessingTime float64
 	RealtimeFactor float64
 	Text           string
+	RawText        string
+	Language       string
 	Chunks         []types.ChunkResult
+	Metadata       *types.Metadata
 }
 
 // TranscribeRequest represents a transcription request
 type TranscribeRequest struct {
-	URL       string `json:"url,omitempty"`
-	Base64    string `json:"base64,omitempty"`
-	Format    string `json:"format"`     // text, json, vtt
-	ChunkSize int    `json:"chunk_size"` // seconds
+	URL       string   `json:"url,omitempty"`
+	Base64    string   `json:"base64,omitempty"`
+	Format    string   `json:"format"`             // see output.Formats
+	ChunkSize int      `json:"chunk_size"`         // seconds
+	Hotwords  []string `json:"hotwords,omitempty"` // boosted phrases
+
+	// Decoding overrides; unset fields use the server's settings
+	DecodingMethod string   `json:"decoding_method,omitempty"`  // greedy, modified_beam_search
+	MaxActivePaths int      `json:"max_active_paths,omitempty"` // modified_beam_search beam size
+	BlankPenalty   *float64 `json:"blank_penalty,omitempty"`
+
+	MinConfidence float32 `json:"min_confidence,omitempty"` // text/vtt only
+	LowConfidence string  `json:"low_confidence,omitempty"` // mark, drop
+	Punctuate     bool    `json:"punctuate,omitempty"`      // restore punctuation and casing
+	ITN           bool    `json:"itn,omitempty"`            // inverse text normalization
+
+	Task           string `json:"task,omitempty"`            // transcribe, translate (Whisper)
+	Language       string `json:"language,omitempty"`        // ISO 639-1 spoken language
+	DetectLanguage bool   `json:"detect_language,omitempty"` // identify the spoken language
+	VAD            bool   `json:"vad,omitempty"`             // transcribe only detected speech
+
+	AudioStream   int    `json:"audio_stream,omitempty"`   // 0 is the first
+	Channel       string `json:"channel,omitempty"`        // left, right or a number from 1
+	SplitChannels bool   `json:"split_channels,omitempty"` // transcribe channels separately
+
+	Preprocess string  `json:"preprocess,omitempty"` // none, normalize, phone, noisy
+	HighPass   float64 `json:"highpass,omitempty"`   // Hz, replaces the preset's cutoff
+	Denoise    bool    `json:"denoise,omitempty"`    // reduce stationary noise
+	Loudnorm   bool    `json:"loudnorm,omitempty"`   // EBU R128 loudness normalization
+
+	// Subtitle segmentation; unset fields use the server defaults
+	MaxCueDuration *float64 `json:"max_cue_duration,omitempty"`
+	MinCueDuration *float64 `json:"min_cue_duration,omitempty"`
+	MaxLineChars   *int     `json:"max_line_chars,omitempty"`
+	MaxLines       *int     `json:"max_lines,omitempty"`
+	MaxCPS         *float64 `json:"max_cps,omitempty"`
+	MinCueGap      *float64 `json:"min_cue_gap,omitempty"`
+	SplitOnComma   *bool    `json:"split_on_comma,omitempty"`
+	SplitOnPause   *float64 `json:"split_on_pause,omitempty"`
+	CuePadding     *float64 `json:"cue_padding,omitempty"`
+	ExtendCues     *bool    `json:"extend_into_silence,omitempty"`
+
+	// Segment-based formats
+	ParagraphGap float64 `json:"paragraph_gap,omitempty"` // seconds
+	Timestamps   bool    `json:"timestamps,omitempty"`    // [HH:MM:SS] prefixes
+	FrameRate    float64 `json:"frame_rate,omitempty"`    // edl and markers timecode
+	AudioSource  string  `json:"audio_source,omitempty"`  // html player source
 }
 
 // TranscribeResponse represents a transcription response
@@ -43,6 +106,9 @@ type TranscribeResponse struct {
 	ProcessingTime float64             `json:"processing_time_seconds"`
 	RealtimeFactor float64             `json:"realtime_factor"`
 	Text           string              `json:"text"`
+	RawText        string              `json:"raw_text,omitempty"`
+	Language       string              `json:"language,omitempty"`
+	Metadata       *types.Metadata     `json:"metadata,omitempty"`
 	Chunks         []types.ChunkResult `json:"chunks,omitempty"`
 }
 
@@ -64,6 +130,38 @@ type ServerOptions struct {
 	MaxUploadMB  int64
 	Workers      int
 	MaxQueueSize int
+
+	// ModelKind is the recognizer's models.KindTransducer or
+	// models.KindWhisper, which decoding overrides are checked against
+	ModelKind string
+
+	// Decoding holds the recognizer's decoding settings, the defaults for
+	// requests. Per-request hotwords need modified_beam_search.
+	Decoding asr.Decoding
+
+	// AllowPunctuation is set when the server loaded a punctuation model
+	AllowPunctuation bool
+
+	// AllowLanguageID is set when the server loaded a language
+	// identification model
+	AllowLanguageID bool
+
+	// AllowVAD is set when the server loaded a voice activity detection
+	// model
+	AllowVAD bool
+
+	// AllowTranslation is set when the recognizer is a Whisper model that
+	// can translate to English
+	AllowTranslation bool
+
+	// Languages lists the languages the model transcribes; empty accepts any
+	Languages []string
+
+	// Cues are the default subtitle rules for requests
+	Cues output.CueOptions
+
+	// URLPolicy restricts the url field of JSON requests
+	URLPolicy URLPolicy
 }
 
 // DefaultServerOptions returns default server options
@@ -74,6 +172,8 @@ func DefaultServerOptions() *ServerOptions {
 		MaxUploadMB:  1024,
 		Workers:      2,
 		MaxQueueSize: 10,
+		URLPolicy:    DefaultURLPolicy(),
+		Cues:         output.DefaultCueOptions(),
 	}
 }
 
@@ -88,6 +188,6 @@ type RecognizerPool interface {
 
 // Recognizer is the interface for ASR recognizer
 type Recognizer interface {
-	Transcribe(audioPath string) (*asr.Result, error)
+	Transcribe(audioPath string, opts asr.StreamOptions) (*asr.Result, error)
 	Close()
 }
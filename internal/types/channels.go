@@ -0,0 +1,81 @@
+package types
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// UtteranceGap is the pause in seconds that ends an utterance when chunks
+// of separately transcribed channels are interleaved
+const UtteranceGap = 1.0
+
+// SplitUtterances splits a chunk at pauses longer than gap between words.
+// Each part spans its words, with token times relative to its own start.
+// Empty chunks yield nothing. RawText cannot be split and is dropped, so
+// normalize after splitting.
+func SplitUtterances(r ChunkResult, gap float64) []ChunkResult {
+	words := GroupWords(r)
+	if len(words) == 0 {
+		if strings.TrimSpace(r.Text) == "" {
+			return nil
+		}
+		return []ChunkResult{r}
+	}
+
+	var parts []ChunkResult
+	first := 0
+	for i := 1; i <= len(words); i++ {
+		if i < len(words) && words[i].Start-words[i-1].End <= gap {
+			continue
+		}
+		lastToken := min(len(r.Tokens), len(r.Timestamps)) - 1
+		if i < len(words) {
+			lastToken = words[i].FirstToken - 1
+		}
+		parts = append(parts, r.slice(words[first].FirstToken, lastToken, words[first].Start, words[i-1].End))
+		first = i
+	}
+	return parts
+}
+
+// slice returns tokens [from, to] as a chunk spanning start to end
+// (absolute seconds)
+func (r ChunkResult) slice(from, to int, start, end float64) ChunkResult {
+	offset := float32(start - r.StartTime)
+	part := ChunkResult{
+		StartTime: start,
+		EndTime:   end,
+		Speaker:   r.Speaker,
+		Language:  r.Language,
+		Tokens:    r.Tokens[from : to+1],
+	}
+	part.Text = strings.TrimSpace(strings.Join(part.Tokens, ""))
+
+	part.Timestamps = make([]float32, to-from+1)
+	for i := range part.Timestamps {
+		part.Timestamps[i] = max(r.Timestamps[from+i]-offset, 0)
+	}
+	if len(r.Durations) == len(r.Tokens) {
+		part.Durations = r.Durations[from : to+1]
+	}
+	if len(r.Confidences) == len(r.Tokens) {
+		part.Confidences = r.Confidences[from : to+1]
+	}
+
+	part.UpdateWords()
+	return part
+}
+
+// Interleave splits chunks of separately transcribed channels (told apart
+// by Speaker) into utterances and orders them by start time
+func Interleave(chunks []ChunkResult) []ChunkResult {
+	var parts []ChunkResult
+	for _, c := range chunks {
+		parts = append(parts, SplitUtterances(c, UtteranceGap)...)
+	}
+	slices.SortStableFunc(parts, func(a, b ChunkResult) int {
+		return cmp.Compare(a.StartTime, b.StartTime)
+	})
+	return parts
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitUtterances(t *testing.T) {
+	r := ChunkResult{
+		StartTime:   10,
+		EndTime:     20,
+		Speaker:     "Left",
+		Text:        "hello there how are you",
+		RawText:     "dropped",
+		Tokens:      []string{" hello", " there", " how", " are", " you"},
+		Timestamps:  []float32{0, 0.5, 3, 3.5, 4},
+		Durations:   []float32{0.4, 0.4, 0.4, 0.4, 0.4},
+		Confidences: []float32{0.9, 0.8, 0.7, 0.6, 0.5},
+	}
+	parts := SplitUtterances(r, 1)
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want 2: %+v", len(parts), parts)
+	}
+
+	first, second := parts[0], parts[1]
+	if first.Text != "hello there" || first.StartTime != 10 || first.EndTime != 10.9 {
+		t.Errorf("first = %q %v-%v, want hello there 10-10.9", first.Text, first.StartTime, first.EndTime)
+	}
+	if second.Text != "how are you" || second.StartTime != 13 || second.EndTime != 14.4 {
+		t.Errorf("second = %q %v-%v, want how are you 13-14.4", second.Text, second.StartTime, second.EndTime)
+	}
+	// Token times are relative to each part
+	if want := []float32{0, 0.5, 1}; !reflect.DeepEqual(second.Timestamps, want) {
+		t.Errorf("second timestamps = %v, want %v", second.Timestamps, want)
+	}
+	if want := []float32{0.7, 0.6, 0.5}; !reflect.DeepEqual(second.Confidences, want) {
+		t.Errorf("second confidences = %v, want %v", second.Confidences, want)
+	}
+	for _, p := range parts {
+		if p.Speaker != "Left" || p.RawText != "" || len(p.Durations) != len(p.Tokens) {
+			t.Errorf("part %q: speaker %q, raw text %q, %d durations", p.Text, p.Speaker, p.RawText, len(p.Durations))
+		}
+	}
+
+	if parts := SplitUtterances(r, 5); len(parts) != 1 || parts[0].Text != r.Text {
+		t.Errorf("with a 5s gap got %+v, want the chunk whole", parts)
+	}
+}
+
+func TestSplitUtterancesWithoutTokens(t *testing.T) {
+	if parts := SplitUtterances(ChunkResult{EndTime: 5}, 1); parts != nil {
+		t.Errorf("empty chunk gave %+v", parts)
+	}
+	r := ChunkResult{EndTime: 5, Text: "no timings"}
+	if parts := SplitUtterances(r, 1); len(parts) != 1 || parts[0].Text != "no timings" {
+		t.Errorf("chunk without tokens gave %+v", parts)
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	left := ChunkResult{
+		StartTime:  0,
+		EndTime:    10,
+		Speaker:    "Left",
+		Tokens:     []string{" hi", " there", " bye"},
+		Timestamps: []float32{0, 0.5, 6},
+	}
+	right := ChunkResult{
+		StartTime:  0,
+		EndTime:    10,
+		Speaker:    "Right",
+		Tokens:     []string{" hello", " again"},
+		Timestamps: []float32{0.2, 3},
+	}
+
+	// Utterances are ordered by start time even when they overlap; equal
+	// starts keep channel order
+	got := Interleave([]ChunkResult{left, right, {StartTime: 0, EndTime: 10, Speaker: "Left"}})
+	want := []struct {
+		speaker, text string
+	}{
+		{"Left", "hi there"},
+		{"Right", "hello"},
+		{"Right", "again"},
+		{"Left", "bye"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d utterances, want %d: %+v", len(got), len(want), got)
+	}
+	for i, u := range got {
+		if u.Speaker != want[i].speaker || u.Text != want[i].text {
+			t.Errorf("utterance %d = %s %q, want %s %q", i, u.Speaker, u.Text, want[i].speaker, want[i].text)
+		}
+	}
+
+	tied := Interleave([]ChunkResult{
+		{StartTime: 1, EndTime: 2, Speaker: "Left", Text: "a"},
+		{StartTime: 1, EndTime: 2, Speaker: "Right", Text: "b"},
+	})
+	if len(tied) != 2 || tied[0].Speaker != "Left" || tied[1].Speaker != "Right" {
+		t.Errorf("tied starts gave %+v, want Left then Right", tied)
+	}
+}
@@ -5,6 +5,39 @@ type ChunkResult struct {
 	StartTime  float64   `json:"start_time"`
 	EndTime    float64   `json:"end_time"`
 	Text       string    `json:"text"`
+	RawText    string    `json:"raw_text,omitempty"` // text before normalization
+	Speaker    string    `json:"speaker,omitempty"`
+	Language   string    `json:"language,omitempty"` // ISO 639-1
 	Timestamps []float32 `json:"timestamps,omitempty"`
 	Tokens     []string  `json:"tokens,omitempty"`
+	Durations  []float32 `json:"durations,omitempty"` // per token, TDT models only
+
+	// Per-token probabilities, present when the model reports them
+	Confidences []float32 `json:"confidences,omitempty"`
+	Confidence  float32   `json:"confidence,omitempty"`
+	Words       []Word    `json:"words,omitempty"`
+}
+
+// Metadata describes how a transcript was produced
+type Metadata struct {
+	ASR         ASRSettings `json:"asr"`
+	Punctuation string      `json:"punctuation,omitempty"` // punctuation model, if applied
+	ITN         bool        `json:"itn,omitempty"`         // inverse text normalization applied
+	LanguageID  string      `json:"language_id,omitempty"` // language identification model, if used
+	Preprocess  string      `json:"preprocess,omitempty"`  // filter chain applied to each chunk, if any
+	VAD         *VADReport  `json:"vad,omitempty"`         // speech detection, if used
+}
+
+// ASRSettings echoes the recognizer settings used for a transcript
+type ASRSettings struct {
+	Model          string  `json:"model"`
+	Provider       string  `json:"provider"`
+	NumThreads     int     `json:"num_threads"`
+	DecodingMethod string  `json:"decoding_method"`
+	Task           string  `json:"task,omitempty"` // Whisper only: transcribe, translate
+	MaxActivePaths int     `json:"max_active_paths,omitempty"`
+	BlankPenalty   float32 `json:"blank_penalty,omitempty"`
+	HotwordsFile   string  `json:"hotwords_file,omitempty"`
+	HotwordsScore  float32 `json:"hotwords_score,omitempty"`
+	Hotwords       int     `json:"hotwords,omitempty"` // per-request hotwords
 }
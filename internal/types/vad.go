@@ -0,0 +1,62 @@
+package types
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Span is a time range in seconds
+type Span struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// VADReport summarizes voice activity detection over a transcript
+type VADReport struct {
+	Model    string       `json:"model"`
+	Speech   float64      `json:"speech"`  // seconds sent to the recognizer
+	Skipped  float64      `json:"skipped"` // seconds of non-speech left out
+	Segments []VADSegment `json:"segments"`
+}
+
+// VADSegment is a stretch of speech or non-speech
+type VADSegment struct {
+	Start  float64 `json:"start"`
+	End    float64 `json:"end"`
+	Speech bool    `json:"speech"`
+}
+
+// NewVADReport builds the report for a file of duration seconds from the
+// speech spans found in it (absolute, possibly overlapping when channels
+// were detected separately). The segments cover the whole file.
+func NewVADReport(model string, speech []Span, duration float64) *VADReport {
+	spans := slices.Clone(speech)
+	slices.SortFunc(spans, func(a, b Span) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+
+	report := &VADReport{Model: model, Segments: []VADSegment{}}
+	pos := 0.0
+	for _, s := range spans {
+		start, end := max(s.Start, pos), min(s.End, duration)
+		if end <= start {
+			continue
+		}
+		if n := len(report.Segments); n > 0 && report.Segments[n-1].Speech && start <= pos {
+			// Overlaps or touches the previous speech segment
+			report.Segments[n-1].End = end
+		} else {
+			if start > pos {
+				report.Segments = append(report.Segments, VADSegment{Start: pos, End: start})
+			}
+			report.Segments = append(report.Segments, VADSegment{Start: start, End: end, Speech: true})
+		}
+		report.Speech += end - start
+		pos = end
+	}
+	if pos < duration {
+		report.Segments = append(report.Segments, VADSegment{Start: pos, End: duration})
+	}
+	report.Skipped = max(duration-report.Speech, 0)
+	return report
+}
@@ -0,0 +1,64 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewVADReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		speech   []Span
+		duration float64
+		want     []VADSegment
+		spoken   float64
+	}{
+		{
+			name:     "no speech",
+			duration: 5,
+			want:     []VADSegment{{Start: 0, End: 5}},
+		},
+		{
+			name:     "gaps",
+			speech:   []Span{{1, 2}, {3, 4}},
+			duration: 5,
+			want: []VADSegment{
+				{Start: 0, End: 1}, {Start: 1, End: 2, Speech: true}, {Start: 2, End: 3},
+				{Start: 3, End: 4, Speech: true}, {Start: 4, End: 5},
+			},
+			spoken: 2,
+		},
+		{
+			name:     "overlapping and unsorted",
+			speech:   []Span{{2, 4}, {0, 3}},
+			duration: 5,
+			want:     []VADSegment{{Start: 0, End: 4, Speech: true}, {Start: 4, End: 5}},
+			spoken:   4,
+		},
+		{
+			name:     "touching",
+			speech:   []Span{{0, 1}, {1, 2}},
+			duration: 2,
+			want:     []VADSegment{{Start: 0, End: 2, Speech: true}},
+			spoken:   2,
+		},
+		{
+			name:     "past the end",
+			speech:   []Span{{4, 7}, {8, 9}},
+			duration: 5,
+			want:     []VADSegment{{Start: 0, End: 4}, {Start: 4, End: 5, Speech: true}},
+			spoken:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewVADReport("silero", tt.speech, tt.duration)
+			if !reflect.DeepEqual(r.Segments, tt.want) {
+				t.Errorf("segments = %+v, want %+v", r.Segments, tt.want)
+			}
+			if r.Speech != tt.spoken || r.Skipped != tt.duration-tt.spoken {
+				t.Errorf("speech %v, skipped %v, want %v and %v", r.Speech, r.Skipped, tt.spoken, tt.duration-tt.spoken)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"math"
+	"strings"
+)
+
+// Word is a word assembled from one or more tokens
+type Word struct {
+	Text       string  `json:"text"`
+	Start      float64 `json:"start"` // absolute seconds
+	End        float64 `json:"end"`   // absolute seconds
+	Confidence float32 `json:"confidence,omitempty"`
+
+	// Token range [FirstToken, LastToken] in the chunk
+	FirstToken int `json:"-"`
+	LastToken  int `json:"-"`
+}
+
+// GroupWords assembles words from the chunk's tokens. A token starting with
+// a space begins a new word; other tokens (subwords, punctuation) continue it.
+func GroupWords(r ChunkResult) []Word {
+	n := min(len(r.Tokens), len(r.Timestamps))
+	words := make([]Word, 0, n)
+
+	for i := 0; i < n; i++ {
+		tok := r.Tokens[i]
+		if len(words) == 0 || strings.HasPrefix(tok, " ") {
+			if strings.TrimSpace(tok) == "" {
+				continue
+			}
+			words = append(words, Word{
+				Start:      r.StartTime + float64(r.Timestamps[i]),
+				FirstToken: i,
+			})
+		}
+
+		w := &words[len(words)-1]
+		w.Text += tok
+		w.LastToken = i
+		w.End = r.StartTime + r.TokenEnd(i)
+	}
+
+	for i := range words {
+		words[i].Text = strings.TrimSpace(words[i].Text)
+		words[i].Start = roundMillis(words[i].Start)
+		words[i].End = roundMillis(words[i].End)
+		if len(r.Confidences) >= n {
+			words[i].Confidence = MeanConfidence(r.Confidences[words[i].FirstToken : words[i].LastToken+1])
+		}
+	}
+
+	return words
+}
+
+// MaxTokenDuration caps token end estimates when the model reports no
+// durations, so the last word before a pause does not absorb the silence
+const MaxTokenDuration = 1.0
+
+// TokenEnd estimates when token i ends, relative to the chunk start: its
+// start plus the model's duration if known, else up to MaxTokenDuration
+// later. It never passes the next token's start or the chunk end.
+func (r ChunkResult) TokenEnd(i int) float64 {
+	start := float64(r.Timestamps[i])
+	limit := r.EndTime - r.StartTime
+	if i+1 < len(r.Timestamps) {
+		limit = float64(r.Timestamps[i+1])
+	}
+
+	end := start + MaxTokenDuration
+	if i < len(r.Durations) && r.Durations[i] > 0 {
+		end = start + float64(r.Durations[i])
+	}
+	return max(min(end, limit), start)
+}
+
+// MeanConfidence returns the geometric mean of token probabilities, which is
+// the probability implied by their mean log-probability
+func MeanConfidence(confidences []float32) float32 {
+	if len(confidences) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, c := range confidences {
+		sum += math.Log(math.Max(float64(c), 1e-10))
+	}
+	return float32(math.Exp(sum / float64(len(confidences))))
+}
+
+func roundMillis(seconds float64) float64 {
+	return math.Round(seconds*1000) / 1000
+}
+
+// UpdateWords recomputes Words and Confidence after tokens have changed
+func (r *ChunkResult) UpdateWords() {
+	r.Words = GroupWords(*r)
+	r.Confidence = MeanConfidence(r.Confidences)
+}
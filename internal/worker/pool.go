@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/hyperpuncher/chough/internal/asr"
 	"github.com/hyperpuncher/chough/internal/audio"
+	"github.com/hyperpuncher/chough/internal/output"
+	"github.com/hyperpuncher/chough/internal/pipeline"
 	"github.com/hyperpuncher/chough/internal/server"
 	"github.com/hyperpuncher/chough/internal/types"
 )
@@ -20,19 +21,34 @@ type Pool struct {
 	workers    int
 	queue      chan *server.Job
 	recognizer *asr.Recognizer
+	punctuator *asr.Punctuator
+	languageID *asr.LanguageIdentifier
+	vad        *asr.VoiceActivityDetector
 	ctx        context.Context
 	cancel     context.CancelFunc
 	wg         sync.WaitGroup
 	busyCount  atomic.Int32
 }
 
+// Models holds the models shared by all workers. Punctuator, LanguageID
+// and VAD are optional.
+type Models struct {
+	Recognizer *asr.Recognizer
+	Punctuator *asr.Punctuator
+	LanguageID *asr.LanguageIdentifier
+	VAD        *asr.VoiceActivityDetector
+}
+
 // NewPool creates a new worker pool
-func NewPool(workers int, queueSize int, recognizer *asr.Recognizer) *Pool {
+func NewPool(workers int, queueSize int, models Models) *Pool {
 	ctx, cancel := context.WithCancel(context.Background())
 	p := &Pool{
 		workers:    workers,
 		queue:      make(chan *server.Job, queueSize),
-		recognizer: recognizer,
+		recognizer: models.Recognizer,
+		punctuator: models.Punctuator,
+		languageID: models.LanguageID,
+		vad:        models.VAD,
 		ctx:        ctx,
 		cancel:     cancel,
 	}
@@ -105,39 +121,70 @@ func (p *Pool) processJob(job *server.Job) {
 
 	startTime := time.Now()
 
-	// Get audio duration
-	duration, err := audio.ProbeDuration(job.FilePath)
+	// The server probed the stream when it accepted the job
+	duration := job.Stream.Duration
+
+	streamOpts := asr.StreamOptions{Hotwords: job.Hotwords, Decoding: &job.Decoding, Language: job.Language, Task: job.Task}
+	if job.DetectLanguage && job.Language == "" {
+		streamOpts.LanguageID = p.languageID
+	}
+	if job.VAD {
+		streamOpts.VAD = p.vad
+	}
+	tracks, err := audio.Tracks(job.Stream, job.Channel, job.SplitChannels)
 	if err != nil {
-		job.Error <- fmt.Errorf("failed to probe audio: %w", err)
+		job.Error <- err
 		return
 	}
 
-	// Build boundaries for chunking
-	boundaries := audio.BuildBoundaries(duration, job.ChunkSize)
-	results := make([]types.ChunkResult, 0, len(boundaries)-1)
-
-	// Process chunks
-	for i := 0; i < len(boundaries)-1; i++ {
-		chunkStart := boundaries[i]
-		chunkEnd := boundaries[i+1]
-
-		if chunkEnd-chunkStart < 0.5 {
-			continue
-		}
+	var punctuator *asr.Punctuator
+	if job.Punctuate {
+		punctuator = p.punctuator
+	}
 
-		result, err := p.transcribeChunk(job.FilePath, chunkStart, chunkEnd-chunkStart)
-		if err != nil {
-			job.Error <- fmt.Errorf("failed to transcribe chunk %d: %w", i+1, err)
-			return
-		}
+	// Build boundaries for chunking
+	chunkSize := job.ChunkSize
+	if limit := p.recognizer.Config.MaxChunkSize(); limit > 0 && chunkSize > limit {
+		chunkSize = limit
+	}
+	boundaries := audio.BuildBoundaries(duration, chunkSize)
+
+	transcript, err := pipeline.Transcribe(job.FilePath, boundaries, pipeline.Options{
+		Recognizer: p.recognizer,
+		Punctuator: punctuator,
+		ITN:        job.ITN,
+		Tracks:     tracks,
+		Chunk:      audio.ChunkOptions{Stream: job.Stream.Index, Filters: job.Filters},
+		Stream:     streamOpts,
+		OnResult: func(chunk types.ChunkResult) error {
+			if job.Chunks == nil {
+				return nil
+			}
+			select {
+			case job.Chunks <- chunk:
+				return nil
+			case <-job.Context.Done():
+				return job.Context.Err()
+			}
+		},
+	})
+	if err != nil {
+		job.Error <- err
+		return
+	}
+	results := transcript.Chunks
 
-		results = append(results, types.ChunkResult{
-			StartTime:  chunkStart,
-			EndTime:    chunkEnd,
-			Text:       result.Text,
-			Timestamps: result.Timestamps,
-			Tokens:     result.Tokens,
-		})
+	meta := &types.Metadata{ASR: p.recognizer.Config.StreamSettings(streamOpts)}
+	meta.ITN = job.ITN
+	meta.Preprocess = job.Filters.String()
+	if punctuator != nil {
+		meta.Punctuation = punctuator.Name
+	}
+	if streamOpts.LanguageID != nil {
+		meta.LanguageID = streamOpts.LanguageID.Name
+	}
+	if streamOpts.VAD != nil {
+		meta.VAD = types.NewVADReport(streamOpts.VAD.Name, transcript.Speech, duration)
 	}
 
 	// Build full text
@@ -162,21 +209,9 @@ func (p *Pool) processJob(job *server.Job) {
 		ProcessingTime: processingTime,
 		RealtimeFactor: rtFactor,
 		Text:           fullText,
+		RawText:        output.FullRawText(results),
+		Language:       output.Language(results),
 		Chunks:         results,
+		Metadata:       meta,
 	}
 }
-
-func (p *Pool) transcribeChunk(audioFile string, start, duration float64) (*asr.Result, error) {
-	tmpDir, err := os.MkdirTemp("", "chough-chunk-*")
-	if err != nil {
-		return nil, err
-	}
-	defer os.RemoveAll(tmpDir)
-
-	chunkFile := filepath.Join(tmpDir, "chunk.wav")
-	if err := audio.ExtractChunkWAV(audioFile, chunkFile, start, duration); err != nil {
-		return nil, err
-	}
-
-	return p.recognizer.Transcribe(chunkFile)
-}